@@ -0,0 +1,42 @@
+package main
+
+import (
+	"UCLA-Rocket-Project/ILAYE/internal/config"
+	"UCLA-Rocket-Project/ILAYE/internal/rpSerial"
+	"fmt"
+	"os"
+
+	"go.uber.org/zap"
+)
+
+// ilaye bridge <port> [addr]
+func runBridge(args []string, cfg *config.Config, log *zap.Logger) int {
+	if len(args) < 1 || len(args) > 2 {
+		fmt.Fprintf(os.Stderr, "usage: ilaye bridge <port> [addr]\n")
+		return 2
+	}
+
+	addr := cfg.Bridge.Addr
+	if len(args) == 2 {
+		addr = args[1]
+	}
+
+	// raw frames reach the uplinker, launch mode included, so the LAN needs a token or an allow list
+	if cfg.Bridge.Token == "" && len(cfg.Bridge.Allow) == 0 && !rpSerial.IsLoopbackAddress(addr) {
+		fmt.Fprintf(os.Stderr, "refusing to share %s on %s without bridge.token or bridge.allow\n", args[0], addr)
+		return 1
+	}
+
+	bridge, err := rpSerial.NewSerialBridge(args[0], BAUD_RATE, cfg.Bridge.Token, cfg.Bridge.Allow, log)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not open %s: %s\n", args[0], err)
+		return 1
+	}
+
+	fmt.Printf("Sharing %s on %s\n", args[0], addr)
+	if err := bridge.Serve(addr); err != nil {
+		fmt.Fprintf(os.Stderr, "bridge stopped: %s\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"UCLA-Rocket-Project/ILAYE/internal/config"
+	"UCLA-Rocket-Project/ILAYE/internal/sdlog"
+	"UCLA-Rocket-Project/ILAYE/internal/telemetry"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ilaye decode -board <analog|digital> -revision <n> [-record <struct>] <log file>
+func runDecode(args []string, cfg *config.Config, log *zap.Logger) int {
+	flags := flag.NewFlagSet("decode", flag.ContinueOnError)
+	board := flags.String("board", "", "board that wrote the log, analog or digital")
+	revision := flags.Uint("revision", 0, "hardware revision of the board")
+	record := flags.String("record", "", "struct the log holds, e.g. IMUData, needed when the board logs several")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: ilaye decode -board <analog|digital> -revision <n> [-record <struct>] <log file>\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 || *board == "" || *revision == 0 || *revision > 255 {
+		flags.Usage()
+		return 2
+	}
+	path := flags.Arg(0)
+
+	layout, err := sdlog.Layout(*board, uint8(*revision), *record)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 2
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not read %s: %s\n", path, err)
+		return 1
+	}
+
+	name := telemetry.SessionName("sd", strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)), time.Now())
+	recorder, err := telemetry.NewSessionRecorder(cfg.Telemetry, name, log)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 1
+	}
+
+	summary, err := sdlog.Decode(raw, *board, uint8(*revision), layout, recorder)
+	if closeErr := recorder.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not decode %s: %s\n", path, err)
+		return 1
+	}
+
+	if err := os.MkdirAll(recorder.Dir, 0o755); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 1
+	}
+	contents, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 1
+	}
+	if err := os.WriteFile(filepath.Join(recorder.Dir, "summary.json"), contents, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "could not write the summary: %s\n", err)
+		return 1
+	}
+
+	printSummary(summary)
+	fmt.Printf("\nDecoded into %s\n", recorder.Dir)
+	return 0
+}
+
+func printSummary(summary *sdlog.Summary) {
+	fmt.Printf("%s records from %s revision %d\n", summary.Record, summary.Board, summary.Revision)
+	fmt.Printf("%d bytes, %d records, %d erased records, %d trailing bytes\n\n", summary.Bytes, summary.Records, summary.Erased, summary.Trailing)
+
+	if summary.Records == 0 {
+		fmt.Printf("no records found\n")
+		return
+	}
+	for _, field := range summary.Fields {
+		fmt.Printf("    %-14s min %-12.6g max %-12.6g mean %-12.6g first %-12.6g last %.6g\n", field.Name, field.Min, field.Max, field.Mean, field.First, field.Last)
+	}
+}
@@ -1,31 +1,135 @@
 package main
 
 import (
+	"UCLA-Rocket-Project/ILAYE/internal/alarms"
+	"UCLA-Rocket-Project/ILAYE/internal/commander"
+	"UCLA-Rocket-Project/ILAYE/internal/config"
 	"UCLA-Rocket-Project/ILAYE/internal/logger"
+	"UCLA-Rocket-Project/ILAYE/internal/metrics"
 	"UCLA-Rocket-Project/ILAYE/internal/rpSerial"
+	"UCLA-Rocket-Project/ILAYE/internal/telemetry"
 	"UCLA-Rocket-Project/ILAYE/internal/terminal"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
 )
 
 const LOG_FILE_PATH = "ILAYE.logs"
+const EVENT_LOG_FILE_PATH = "ILAYE.events"
+const CONFIG_FILE_PATH = "ilaye.yaml"
 const BAUD_RATE = 115200
 
 var STOP_SEQUENCE = []byte{'\r', '\n'}
 
 func main() {
-	log, err := logger.NewLogger(LOG_FILE_PATH)
+	debugLogs := logger.NewRingBuffer(logger.RING_BUFFER_SIZE)
+	log, err := logger.NewLogger(LOG_FILE_PATH, debugLogs)
 	if err != nil {
 		panic(err)
 	}
 	defer log.Sync()
 
+	// frames that were not the reply to a command, kept apart so they are easy to find
+	events, err := logger.NewLogger(EVENT_LOG_FILE_PATH, nil)
+	if err != nil {
+		panic(err)
+	}
+	defer events.Sync()
+
+	cfg, err := config.Load(CONFIG_FILE_PATH)
+	if err != nil {
+		log.Fatal("Error loading config file", zap.Error(err), zap.String("path", CONFIG_FILE_PATH))
+	}
+
+	// these never send commands, so they skip the metrics listener, alarm log and observers below
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "bridge":
+			exitCode := runBridge(os.Args[2:], cfg, log)
+			log.Sync()
+			os.Exit(exitCode)
+		case "decode":
+			exitCode := runDecode(os.Args[2:], cfg, log)
+			log.Sync()
+			os.Exit(exitCode)
+		}
+	}
+
+	if cfg.Metrics.Addr != "" {
+		go metrics.Serve(cfg.Metrics.Addr, log)
+	}
+
+	rules, err := alarms.LoadRules(cfg.Alarms.File)
+	if err != nil {
+		log.Fatal("Error loading alarm rules", zap.Error(err), zap.String("path", cfg.Alarms.File))
+	}
+	alarmEngine, err := alarms.NewEngine(rules, cfg.Alarms.LogFile, log)
+	if err != nil {
+		log.Fatal("Error opening alarm log", zap.Error(err), zap.String("path", cfg.Alarms.LogFile))
+	}
+	go alarmEngine.WatchLink()
+
+	matcher := rpSerial.Matcher{Reply: commander.MatchesResponse, Telemetry: commander.IsTelemetry}
 	connector := func(port string) (terminal.SerialReaderWriter, error) {
-		serial := rpSerial.NewRPSerial(port, BAUD_RATE, log)
+		observers := []commander.Observer{metrics.Observer{}, alarmEngine.Link(port)}
+		if cfg.Telemetry.RecordPolled {
+			// every connection records its readings into a directory of its own
+			recorder, err := telemetry.NewSessionRecorder(cfg.Telemetry, telemetry.SessionName("polled", port, time.Now()), log)
+			if err != nil {
+				return nil, err
+			}
+			observers = append(observers, recorder)
+		}
+
+		if rpSerial.IsNetworkAddress(port) {
+			serial, err := rpSerial.NewTCPSerial(port, cfg.Bridge.Token, matcher, log, events)
+			if err != nil {
+				return nil, err
+			}
+			return commander.Observe(serial, observers...), nil
+		}
+
+		serial, err := rpSerial.NewRPSerial(port, BAUD_RATE, matcher, log, events)
+		if err != nil {
+			return nil, err
+		}
 
 		serial.ResetInputBuffer()
 		serial.ResetOutputBuffer()
 
-		return serial, nil
+		return commander.Observe(serial, observers...), nil
+	}
+
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "procedure":
+			exitCode := runProcedure(os.Args[2:], connector, cfg, log)
+			log.Sync()
+			os.Exit(exitCode)
+		case "serve":
+			exitCode := runServe(os.Args[2:], connector, cfg, log)
+			log.Sync()
+			os.Exit(exitCode)
+		}
 	}
 
-	terminal.StartApplication(rpSerial.ListPorts, connector, log)
+	terminal.StartApplication(rpSerial.ListPorts, connector, cfg, alarmEngine, log, debugLogs)
+}
+
+// close c and exit on ctrl+c or SIGTERM, parquet recordings of a connection are only readable once closed
+func closeOnSignal(c io.Closer, log *zap.Logger) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		if err := c.Close(); err != nil {
+			log.Error("Could not close the connection", zap.Error(err))
+		}
+		log.Sync()
+		os.Exit(130)
+	}()
 }
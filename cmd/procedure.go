@@ -0,0 +1,73 @@
+package main
+
+import (
+	"UCLA-Rocket-Project/ILAYE/internal/checkout"
+	"UCLA-Rocket-Project/ILAYE/internal/config"
+	"UCLA-Rocket-Project/ILAYE/internal/procedure"
+	"UCLA-Rocket-Project/ILAYE/internal/terminal"
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+
+	"go.uber.org/zap"
+)
+
+// prompts the operator on the terminal and waits for enter
+type stdinOperator struct {
+	reader *bufio.Reader
+}
+
+func (o stdinOperator) Prompt(message string) error {
+	fmt.Printf(">>> %s (press enter)\n", message)
+	_, err := o.reader.ReadString('\n')
+	return err
+}
+
+// ilaye procedure <file> <port>
+func runProcedure(args []string, connector terminal.PortConnector, cfg *config.Config, log *zap.Logger) int {
+	if len(args) != 2 {
+		fmt.Fprintf(os.Stderr, "usage: ilaye procedure <file> <port>\n")
+		return 2
+	}
+
+	proc, err := procedure.Load(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not load procedure: %s\n", err)
+		return 1
+	}
+	section, _ := proc.CheckoutSection()
+
+	// validate before touching the port so a typo never leaves the vehicle half way through
+	if err := proc.Validate(checkout.Checkout{Config: cfg, Section: section}); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid procedure: %s\n", err)
+		return 1
+	}
+
+	conn, err := connector(args[1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not connect to %s: %s\n", args[1], err)
+		return 1
+	}
+	if closer, ok := conn.(io.Closer); ok {
+		defer closer.Close()
+		closeOnSignal(closer, log)
+	}
+
+	versions, ok := checkout.Handshake(conn, os.Stdout, cfg)
+	if !ok {
+		return 1
+	}
+
+	// the procedure may name entries for a board revision that is not fitted
+	c := checkout.Checkout{Conn: conn, Config: cfg, Section: section, Versions: versions}
+	if err := proc.Validate(c); err != nil {
+		fmt.Fprintf(os.Stderr, "procedure does not match the fitted boards: %s\n", err)
+		return 1
+	}
+
+	if !procedure.Run(proc, c, os.Stdout, stdinOperator{reader: bufio.NewReader(os.Stdin)}) {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,537 @@
+/**
+Generates the protocol code shared with the firmware from protocol/protocol.yaml
+
+  internal/globals/globals_gen.go      opcodes, error codes, host actions and telemetry types
+  internal/commander/protocol_gen.go   reply structs and which opcode replies with which
+  protocol/ilaye_protocol.h            the same opcodes and packed structs for the firmware
+
+Run with go generate ./... from the repository root.
+*/
+
+//go:generate go run . -root ../..
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Schema struct {
+	// bumped whenever a struct or opcode changes meaning, checked by the version handshake
+	ProtocolVersion uint16 `yaml:"protocol_version"`
+
+	Commands    []CommandGroup `yaml:"commands"`
+	ErrorCodes  []ErrorCode    `yaml:"error_codes"`
+	HostActions []HostAction   `yaml:"host_actions"`
+	Telemetry   []Telemetry    `yaml:"telemetry"`
+	Structs     []Struct       `yaml:"structs"`
+}
+
+type CommandGroup struct {
+	Group string `yaml:"group"`
+
+	// board that answers these opcodes and its hardware revision, empty for the radio and shared commands
+	Board    string `yaml:"board"`
+	Revision uint8  `yaml:"revision"`
+
+	// tick of the 32 bit Timestamp in the sensor readings of this board, e.g. 1ms, empty when not known
+	TimestampUnit string `yaml:"timestamp_unit"`
+
+	// set once the unit has been checked against the firmware, until then checks built on it only warn
+	TimestampUnitConfirmed bool `yaml:"timestamp_unit_confirmed"`
+
+	Commands []Command `yaml:"commands"`
+}
+
+type Command struct {
+	Name     string `yaml:"name"`
+	Opcode   uint8  `yaml:"opcode"`
+	Request  string `yaml:"request"`
+	Response string `yaml:"response"`
+}
+
+type ErrorCode struct {
+	Name     string `yaml:"name"`
+	Code     uint8  `yaml:"code"`
+	Response string `yaml:"response"`
+}
+
+type HostAction struct {
+	Name   string `yaml:"name"`
+	Opcode uint8  `yaml:"opcode"`
+
+	// only for actions that need one board revision
+	Board    string `yaml:"board"`
+	Revision uint8  `yaml:"revision"`
+}
+
+type Telemetry struct {
+	Name   string `yaml:"name"`
+	Type   uint8  `yaml:"type"`
+	Struct string `yaml:"struct"`
+
+	// board that streams the packet, for the telemetry view
+	Board string `yaml:"board"`
+}
+
+// every telemetry packet starts with this struct
+const TELEMETRY_HEADER = "telemetryHeader"
+
+type Struct struct {
+	Name   string  `yaml:"name"`
+	Doc    string  `yaml:"doc"`
+	Fields []Field `yaml:"fields"`
+}
+
+type Field struct {
+	Name  string `yaml:"name"`
+	Type  string `yaml:"type"`
+	Count int    `yaml:"count"`
+
+	// name in the C header when the snake case of Name doesn't work, e.g. a C keyword
+	CName string `yaml:"c_name"`
+}
+
+func (f Field) cName() string {
+	if f.CName != "" {
+		return f.CName
+	}
+	return snakeCase(f.Name)
+}
+
+// C keywords a field name could collide with once snake cased
+var cKeywords = map[string]bool{
+	"auto": true, "break": true, "case": true, "char": true, "const": true, "continue": true,
+	"default": true, "do": true, "double": true, "else": true, "enum": true, "extern": true,
+	"float": true, "for": true, "goto": true, "if": true, "int": true, "long": true,
+	"register": true, "return": true, "short": true, "signed": true, "sizeof": true, "static": true,
+	"struct": true, "switch": true, "typedef": true, "union": true, "unsigned": true, "void": true,
+	"volatile": true, "while": true,
+}
+
+// wire types and their C equivalent and width in bytes
+var types = map[string]struct {
+	C    string
+	Size int
+}{
+	"uint8":   {"uint8_t", 1},
+	"int8":    {"int8_t", 1},
+	"uint16":  {"uint16_t", 2},
+	"int16":   {"int16_t", 2},
+	"uint32":  {"uint32_t", 4},
+	"int32":   {"int32_t", 4},
+	"uint64":  {"uint64_t", 8},
+	"int64":   {"int64_t", 8},
+	"float32": {"float", 4},
+	"float64": {"double", 8},
+}
+
+const GENERATED_HEADER = "Code generated by cmd/protogen from protocol/protocol.yaml. DO NOT EDIT."
+
+func main() {
+	root := flag.String("root", ".", "repository root")
+	flag.Parse()
+
+	schema, err := load(filepath.Join(*root, "protocol", "protocol.yaml"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[protogen]: %s\n", err)
+		os.Exit(1)
+	}
+
+	outputs := []struct {
+		path     string
+		generate func(*Schema) ([]byte, error)
+	}{
+		{filepath.Join(*root, "internal", "globals", "globals_gen.go"), generateGlobals},
+		{filepath.Join(*root, "internal", "commander", "protocol_gen.go"), generateCommander},
+		{filepath.Join(*root, "protocol", "ilaye_protocol.h"), generateHeader},
+	}
+	for _, output := range outputs {
+		contents, err := output.generate(schema)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[protogen]: %s: %s\n", output.path, err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(output.path, contents, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "[protogen]: %s\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+func load(path string) (*Schema, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var schema Schema
+	if err := yaml.Unmarshal(contents, &schema); err != nil {
+		return nil, err
+	}
+	return &schema, schema.validate()
+}
+
+// catch mistakes that would otherwise only show up as a compile error in generated code
+func (s *Schema) validate() error {
+	structs := map[string]bool{}
+	for _, st := range s.Structs {
+		if structs[st.Name] {
+			return fmt.Errorf("struct %s is defined twice", st.Name)
+		}
+		structs[st.Name] = true
+		for _, field := range st.Fields {
+			if _, ok := types[field.Type]; !ok {
+				return fmt.Errorf("%s.%s: unknown type %q", st.Name, field.Name, field.Type)
+			}
+			if cKeywords[field.cName()] {
+				return fmt.Errorf("%s.%s: %q is a C keyword, set c_name", st.Name, field.Name, field.cName())
+			}
+		}
+	}
+
+	if s.ProtocolVersion == 0 {
+		return fmt.Errorf("protocol_version has to be set")
+	}
+
+	opcodes := map[uint8]string{}
+	claim := func(name string, opcode uint8) error {
+		if other, ok := opcodes[opcode]; ok {
+			return fmt.Errorf("%s and %s share 0x%02X", name, other, opcode)
+		}
+		opcodes[opcode] = name
+		return nil
+	}
+	for _, group := range s.Commands {
+		if group.TimestampUnit != "" {
+			if unit, err := time.ParseDuration(group.TimestampUnit); err != nil || unit <= 0 {
+				return fmt.Errorf("%s: timestamp_unit %q is not a positive duration", group.Group, group.TimestampUnit)
+			}
+		}
+		for _, cmd := range group.Commands {
+			if err := claim(cmd.Name, cmd.Opcode); err != nil {
+				return err
+			}
+			for _, ref := range []string{cmd.Request, cmd.Response} {
+				if ref != "" && !structs[ref] {
+					return fmt.Errorf("%s: unknown struct %s", cmd.Name, ref)
+				}
+			}
+		}
+	}
+	for _, action := range s.HostActions {
+		if err := claim(action.Name, action.Opcode); err != nil {
+			return err
+		}
+	}
+	for _, code := range s.ErrorCodes {
+		if code.Response != "" && !structs[code.Response] {
+			return fmt.Errorf("%s: unknown struct %s", code.Name, code.Response)
+		}
+		if err := claim(code.Name, code.Code); err != nil {
+			return err
+		}
+	}
+
+	// the type byte shares the first byte of a frame with replies, so it has to stay clear of both
+	if len(s.Telemetry) > 0 && !structs[TELEMETRY_HEADER] {
+		return fmt.Errorf("telemetry needs the %s struct", TELEMETRY_HEADER)
+	}
+	for _, packet := range s.Telemetry {
+		if !structs[packet.Struct] {
+			return fmt.Errorf("%s: unknown struct %s", packet.Name, packet.Struct)
+		}
+		if err := claim(packet.Name, packet.Type); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s Struct) size() int {
+	size := 0
+	for _, field := range s.Fields {
+		size += types[field.Type].Size * max(field.Count, 1)
+	}
+	return size
+}
+
+func writeComment(b *bytes.Buffer, indent string, doc string) {
+	if doc == "" {
+		return
+	}
+	for _, line := range strings.Split(doc, "\n") {
+		fmt.Fprintf(b, "%s// %s\n", indent, line)
+	}
+}
+
+func generateGlobals(s *Schema) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// %s\n\npackage globals\n\n", GENERATED_HEADER)
+
+	fmt.Fprintf(&b, "const (\n")
+	for i, group := range s.Commands {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		writeComment(&b, "\t", group.Group)
+		for _, cmd := range group.Commands {
+			fmt.Fprintf(&b, "\t%s = 0x%02X\n", cmd.Name, cmd.Opcode)
+		}
+	}
+	b.WriteString(")\n\n")
+
+	fmt.Fprintf(&b, "// version of the protocol this build speaks, the radio has to report the same\nconst PROTOCOL_VERSION = %d\n\n", s.ProtocolVersion)
+
+	b.WriteString("// replies the radio sends in place of the expected struct\nconst (\n")
+	for _, code := range s.ErrorCodes {
+		fmt.Fprintf(&b, "\t%s = 0x%02X\n", code.Name, code.Code)
+	}
+	b.WriteString(")\n\n")
+
+	b.WriteString("// host side actions, these are never sent over the radio and only\n// identify checks that ILAYE runs on its own across several commands\nconst (\n")
+	for _, action := range s.HostActions {
+		fmt.Fprintf(&b, "\t%s = 0x%02X\n", action.Name, action.Opcode)
+	}
+	b.WriteString(")\n\n")
+
+	b.WriteString("// types of the telemetry packets the boards stream in normal mode\nconst (\n")
+	for _, packet := range s.Telemetry {
+		fmt.Fprintf(&b, "\t%s = 0x%02X\n", packet.Name, packet.Type)
+	}
+	b.WriteString(")\n")
+
+	return format.Source(b.Bytes())
+}
+
+func generateCommander(s *Schema) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// %s\n\npackage commander\n\nimport (\n\t\"UCLA-Rocket-Project/ILAYE/internal/globals\"\n\t\"time\"\n)\n\n", GENERATED_HEADER)
+
+	for _, st := range s.Structs {
+		writeComment(&b, "", st.Doc)
+		fmt.Fprintf(&b, "type %s struct {\n", st.Name)
+		for _, field := range st.Fields {
+			if field.Count > 0 {
+				fmt.Fprintf(&b, "\t%s [%d]%s\n", field.Name, field.Count, field.Type)
+			} else {
+				fmt.Fprintf(&b, "\t%s %s\n", field.Name, field.Type)
+			}
+		}
+		b.WriteString("}\n\n")
+	}
+
+	// group opcodes by the struct they map to, keeping schema order
+	layoutSwitch := func(name string, doc string, pick func(Command) string) {
+		order := []string{}
+		opcodes := map[string][]string{}
+		for _, group := range s.Commands {
+			for _, cmd := range group.Commands {
+				layout := pick(cmd)
+				if layout == "" {
+					continue
+				}
+				if _, ok := opcodes[layout]; !ok {
+					order = append(order, layout)
+				}
+				opcodes[layout] = append(opcodes[layout], "globals."+cmd.Name)
+			}
+		}
+
+		fmt.Fprintf(&b, "// %s\nfunc %s(opcode byte) (any, bool) {\n\tswitch opcode {\n", doc, name)
+		for _, layout := range order {
+			fmt.Fprintf(&b, "\tcase %s:\n\t\treturn &%s{}, true\n", strings.Join(opcodes[layout], ", "), layout)
+		}
+		b.WriteString("\t}\n\treturn nil, false\n}\n\n")
+	}
+	layoutSwitch("responseLayout", "the struct a reply to opcode is decoded into", func(c Command) string { return c.Response })
+	layoutSwitch("requestLayout", "the struct a request frame for opcode carries, only for commands with a payload", func(c Command) string { return c.Request })
+
+	b.WriteString("// every reply struct, for replies to opcodes that have no known layout yet\nfunc knownLayouts() []any {\n\treturn []any{\n")
+	for _, st := range s.Structs {
+		fmt.Fprintf(&b, "\t\t&%s{},\n", st.Name)
+	}
+	b.WriteString("\t}\n}\n\n")
+
+	b.WriteString("// names of the error codes the radio replies with instead of the expected struct\nfunc ErrorCodeName(code byte) (string, bool) {\n\tswitch code {\n")
+	for _, code := range s.ErrorCodes {
+		fmt.Fprintf(&b, "\tcase globals.%s:\n\t\treturn %q, true\n", code.Name, code.Name)
+	}
+	b.WriteString("\t}\n\treturn \"\", false\n}\n\n")
+
+	b.WriteString("// the struct an error reply carries, a bare error code decodes as an ackReply\nfunc errorCodeLayout(code byte) any {\n\tswitch code {\n")
+	for _, code := range s.ErrorCodes {
+		if code.Response != "" {
+			fmt.Fprintf(&b, "\tcase globals.%s:\n\t\treturn &%s{}\n", code.Name, code.Response)
+		}
+	}
+	b.WriteString("\t}\n\treturn &ackReply{}\n}\n\n")
+
+	b.WriteString("// names of the opcodes sent to the radio and of the host actions\nfunc OpcodeName(opcode byte) (string, bool) {\n\tswitch opcode {\n")
+	for _, group := range s.Commands {
+		for _, cmd := range group.Commands {
+			fmt.Fprintf(&b, "\tcase globals.%s:\n\t\treturn %q, true\n", cmd.Name, cmd.Name)
+		}
+	}
+	for _, action := range s.HostActions {
+		fmt.Fprintf(&b, "\tcase globals.%s:\n\t\treturn %q, true\n", action.Name, action.Name)
+	}
+	b.WriteString("\t}\n\treturn \"\", false\n}\n\n")
+
+	// opcodes grouped by the board revision they need, in schema order
+	order := []string{}
+	opcodes := map[string][]string{}
+	add := func(board string, revision uint8, name string) {
+		if board == "" {
+			return
+		}
+		key := fmt.Sprintf("%q, %d", board, revision)
+		if _, ok := opcodes[key]; !ok {
+			order = append(order, key)
+		}
+		opcodes[key] = append(opcodes[key], "globals."+name)
+	}
+	for _, group := range s.Commands {
+		for _, cmd := range group.Commands {
+			add(group.Board, group.Revision, cmd.Name)
+		}
+	}
+	for _, action := range s.HostActions {
+		add(action.Board, action.Revision, action.Name)
+	}
+
+	b.WriteString("// the board that has to be fitted for opcode to work and its hardware revision,\n// an empty board means the radio or every revision\nfunc OpcodeBoard(opcode byte) (string, uint8) {\n\tswitch opcode {\n")
+	for _, key := range order {
+		fmt.Fprintf(&b, "\tcase %s:\n\t\treturn %s\n", strings.Join(opcodes[key], ", "), key)
+	}
+	b.WriteString("\t}\n\treturn \"\", 0\n}\n\n")
+
+	b.WriteString("// tick of the 32 bit sensor timestamp in the reply to opcode and whether it was confirmed against\n// the firmware, ok is false when the protocol does not give one\nfunc sensorTimestampUnit(opcode byte) (unit time.Duration, confirmed bool, ok bool) {\n\tswitch opcode {\n")
+	for _, group := range s.Commands {
+		if group.TimestampUnit == "" {
+			continue
+		}
+		names := []string{}
+		for _, cmd := range group.Commands {
+			names = append(names, "globals."+cmd.Name)
+		}
+		unit, _ := time.ParseDuration(group.TimestampUnit)
+		fmt.Fprintf(&b, "\tcase %s:\n\t\treturn %s, %t, true\n", strings.Join(names, ", "), durationLiteral(unit), group.TimestampUnitConfirmed)
+	}
+	b.WriteString("\t}\n\treturn 0, false, false\n}\n\n")
+
+	b.WriteString("// the struct after the header of a telemetry packet, its name in the schema and the board that streams it\nfunc telemetryLayout(packetType byte) (any, string, string, bool) {\n\tswitch packetType {\n")
+	for _, packet := range s.Telemetry {
+		fmt.Fprintf(&b, "\tcase globals.%s:\n\t\treturn &%s{}, %q, %q, true\n", packet.Name, packet.Struct, packet.Name, packet.Board)
+	}
+	b.WriteString("\t}\n\treturn nil, \"\", \"\", false\n}\n\n")
+
+	b.WriteString("// every telemetry type, in schema order\nfunc TelemetryTypes() []byte {\n\treturn []byte{\n")
+	for _, packet := range s.Telemetry {
+		fmt.Fprintf(&b, "\t\tglobals.%s,\n", packet.Name)
+	}
+	b.WriteString("\t}\n}\n")
+
+	return format.Source(b.Bytes())
+}
+
+// 1ms -> time.Millisecond, 250us -> 250 * time.Microsecond
+func durationLiteral(d time.Duration) string {
+	units := []struct {
+		size time.Duration
+		name string
+	}{
+		{time.Second, "time.Second"},
+		{time.Millisecond, "time.Millisecond"},
+		{time.Microsecond, "time.Microsecond"},
+	}
+	for _, unit := range units {
+		if d%unit.size == 0 {
+			if d == unit.size {
+				return unit.name
+			}
+			return fmt.Sprintf("%d * %s", d/unit.size, unit.name)
+		}
+	}
+	return fmt.Sprintf("%d * time.Nanosecond", d)
+}
+
+// IMUData -> imu_data, ModeTransitionErrorResponse -> mode_transition_error_response
+func snakeCase(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prevLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || (unicode.IsUpper(runes[i-1]) && nextLower) {
+				b.WriteRune('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+func generateHeader(s *Schema) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "/* %s */\n\n", GENERATED_HEADER)
+	b.WriteString("#ifndef ILAYE_PROTOCOL_H\n#define ILAYE_PROTOCOL_H\n\n#include <stdint.h>\n\n")
+	b.WriteString("/* every command frame ends with this terminator */\n#define ILAYE_FRAME_TERMINATOR \"+++\"\n\n")
+	fmt.Fprintf(&b, "/* reported in the version reply, ILAYE refuses a radio on any other version */\n#define ILAYE_PROTOCOL_VERSION %d\n\n", s.ProtocolVersion)
+
+	for _, group := range s.Commands {
+		fmt.Fprintf(&b, "/* %s */\n", group.Group)
+		for _, cmd := range group.Commands {
+			fmt.Fprintf(&b, "#define %s 0x%02X\n", cmd.Name, cmd.Opcode)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("/* replies sent in place of the expected struct */\n")
+	for _, code := range s.ErrorCodes {
+		fmt.Fprintf(&b, "#define %s 0x%02X\n", code.Name, code.Code)
+	}
+	b.WriteString("\n")
+
+	b.WriteString("/* telemetry packets streamed in normal mode, a telemetry_header_t then the struct */\n")
+	for _, packet := range s.Telemetry {
+		fmt.Fprintf(&b, "#define %s 0x%02X /* %s_t */\n", packet.Name, packet.Type, snakeCase(packet.Struct))
+	}
+	b.WriteString("\n")
+
+	for _, st := range s.Structs {
+		if st.Doc != "" {
+			b.WriteString("/*\n")
+			for _, line := range strings.Split(st.Doc, "\n") {
+				fmt.Fprintf(&b, " * %s\n", line)
+			}
+			b.WriteString(" */\n")
+		}
+		cName := snakeCase(st.Name) + "_t"
+		b.WriteString("typedef struct __attribute__((packed)) {\n")
+		for _, field := range st.Fields {
+			if field.Count > 0 {
+				fmt.Fprintf(&b, "    %s %s[%d];\n", types[field.Type].C, field.cName(), field.Count)
+			} else {
+				fmt.Fprintf(&b, "    %s %s;\n", types[field.Type].C, field.cName())
+			}
+		}
+		fmt.Fprintf(&b, "} %s;\n", cName)
+		fmt.Fprintf(&b, "_Static_assert(sizeof(%s) == %d, \"%s must match ILAYE\");\n\n", cName, st.size(), cName)
+	}
+
+	b.WriteString("#endif /* ILAYE_PROTOCOL_H */\n")
+	return b.Bytes(), nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// the checked in files have to be what protocol.yaml generates, otherwise someone edited one by hand
+// or forgot to run go generate
+func TestGeneratedFilesMatchSchema(t *testing.T) {
+	schema, err := load(filepath.Join("..", "..", "protocol", "protocol.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path     string
+		generate func(*Schema) ([]byte, error)
+	}{
+		{filepath.Join("..", "..", "internal", "globals", "globals_gen.go"), generateGlobals},
+		{filepath.Join("..", "..", "internal", "commander", "protocol_gen.go"), generateCommander},
+		{filepath.Join("..", "..", "protocol", "ilaye_protocol.h"), generateHeader},
+	}
+	for _, test := range tests {
+		t.Run(filepath.Base(test.path), func(t *testing.T) {
+			generated, err := test.generate(schema)
+			if err != nil {
+				t.Fatal(err)
+			}
+			onDisk, err := os.ReadFile(test.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(generated, onDisk) {
+				t.Fatalf("%s is out of date, run go generate ./...", test.path)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	const structs = `
+structs:
+  - name: ackReply
+    fields: [{ name: Opcode, type: uint8 }]
+  - name: telemetryHeader
+    fields: [{ name: Type, type: uint8 }]
+`
+	tests := []struct {
+		name   string
+		schema string
+		err    string // empty when the schema is valid
+	}{
+		{"valid", `
+protocol_version: 1
+commands:
+  - group: General
+    timestamp_unit: 1ms
+    commands: [{ name: CMD_A, opcode: 0x01, response: ackReply }]
+telemetry: [{ name: TLM_A, type: 0xA0, struct: ackReply }]
+`, ""},
+		{"no protocol version", `
+commands:
+  - group: General
+    commands: [{ name: CMD_A, opcode: 0x01, response: ackReply }]
+`, "protocol_version has to be set"},
+		{"shared opcode", `
+protocol_version: 1
+commands:
+  - group: General
+    commands: [{ name: CMD_A, opcode: 0x01 }, { name: CMD_B, opcode: 0x01 }]
+`, "CMD_B and CMD_A share 0x01"},
+		{"host action on a command opcode", `
+protocol_version: 1
+commands:
+  - group: General
+    commands: [{ name: CMD_A, opcode: 0xE0 }]
+host_actions: [{ name: HOST_A, opcode: 0xE0 }]
+`, "HOST_A and CMD_A share 0xE0"},
+		{"telemetry type on an error code", `
+protocol_version: 1
+error_codes: [{ name: ERR_A, code: 0xFF }]
+telemetry: [{ name: TLM_A, type: 0xFF, struct: ackReply }]
+`, "TLM_A and ERR_A share 0xFF"},
+		{"unknown reply struct", `
+protocol_version: 1
+commands:
+  - group: General
+    commands: [{ name: CMD_A, opcode: 0x01, response: missing }]
+`, "CMD_A: unknown struct missing"},
+		{"bad timestamp unit", `
+protocol_version: 1
+commands:
+  - group: General
+    timestamp_unit: -1ms
+`, "timestamp_unit \"-1ms\" is not a positive duration"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var schema Schema
+			if err := yaml.Unmarshal([]byte(test.schema+structs), &schema); err != nil {
+				t.Fatal(err)
+			}
+			err := schema.validate()
+			if test.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("error %v, want one containing %q", err, test.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"UCLA-Rocket-Project/ILAYE/internal/commander"
+	"UCLA-Rocket-Project/ILAYE/internal/config"
+	"UCLA-Rocket-Project/ILAYE/internal/rpSerial"
+	"UCLA-Rocket-Project/ILAYE/internal/server"
+	"UCLA-Rocket-Project/ILAYE/internal/terminal"
+	"fmt"
+	"os"
+
+	"go.uber.org/zap"
+)
+
+// ilaye serve [addr]
+func runServe(args []string, connector terminal.PortConnector, cfg *config.Config, log *zap.Logger) int {
+	if len(args) > 1 {
+		fmt.Fprintf(os.Stderr, "usage: ilaye serve [addr]\n")
+		return 2
+	}
+
+	addr := cfg.Server.Addr
+	if len(args) == 1 {
+		addr = args[0]
+	}
+
+	// launch mode is one request away, so the API is only left open on this machine
+	if cfg.Server.Token == "" && !rpSerial.IsLoopbackAddress(addr) {
+		fmt.Fprintf(os.Stderr, "refusing to serve on %s without server.token, set one or listen on 127.0.0.1\n", addr)
+		return 1
+	}
+
+	connect := func(port string) (commander.SerialReaderWriter, error) {
+		return connector(port)
+	}
+	api := server.New(cfg, log, rpSerial.ListPorts, connect)
+	closeOnSignal(api, log)
+
+	fmt.Printf("Serving the ILAYE API on %s\n", addr)
+	if err := api.ListenAndServe(addr); err != nil {
+		fmt.Fprintf(os.Stderr, "could not serve on %s: %s\n", addr, err)
+		return 1
+	}
+	return 0
+}
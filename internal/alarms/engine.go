@@ -0,0 +1,306 @@
+package alarms
+
+import (
+	"UCLA-Rocket-Project/ILAYE/internal/commander"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// how often link loss rules are checked between replies
+const LINK_CHECK_INTERVAL = time.Second
+
+type Alarm struct {
+	Rule    string
+	Link    string // port or bridge address of the connection that tripped it
+	Message string
+	Raised  time.Time
+
+	// the condition still holds, an alarm that returned to normal stays until cleared
+	Active bool
+	Acked  bool
+}
+
+// evaluates the rules on every reply of every connection, each attached with commander.Observe(conn, engine.Link(port)),
+// and on every telemetry packet streamed over it, attached with engine.TelemetryLink(port)
+type Engine struct {
+	rules   []Rule
+	logFile *os.File
+	log     *zap.Logger
+
+	mu     sync.Mutex
+	alarms []*Alarm
+	links  map[string]*link
+}
+
+// rule state of one connection, so two uplinkers never trip each other's rules
+type link struct {
+	engine *Engine
+	name   string
+
+	// last value each not_growing rule saw
+	previous map[string]float64
+
+	// first command that has not been answered yet, zero while the link is quiet
+	pendingSince time.Time
+}
+
+// alarms are appended to logPath so the record survives a restart of ILAYE
+func NewEngine(rules []Rule, logPath string, log *zap.Logger) (*Engine, error) {
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Engine{
+		rules:   rules,
+		logFile: logFile,
+		log:     log,
+		links:   map[string]*link{},
+	}, nil
+}
+
+func (e *Engine) Rules() []Rule {
+	return e.rules
+}
+
+// the observer of the connection to name, reconnecting to the same port picks up where it left off
+func (e *Engine) Link(name string) commander.Observer {
+	return e.link(name)
+}
+
+// the same rule state as Link, for the packets a telemetry stream decodes on that connection
+func (e *Engine) TelemetryLink(name string) commander.TelemetryObserver {
+	return e.link(name)
+}
+
+func (e *Engine) link(name string) *link {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	l, ok := e.links[name]
+	if !ok {
+		l = &link{engine: e, name: name, previous: map[string]float64{}}
+		e.links[name] = l
+	}
+	return l
+}
+
+// check the link loss rules until the program exits, replies alone cannot notice a silent link
+func (e *Engine) WatchLink() {
+	ticker := time.NewTicker(LINK_CHECK_INTERVAL)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		e.mu.Lock()
+		for _, l := range e.links {
+			l.checkLink(now)
+		}
+		e.mu.Unlock()
+	}
+}
+
+func (l *link) Sent(opcode byte, at time.Time) {
+	l.engine.mu.Lock()
+	defer l.engine.mu.Unlock()
+	if l.pendingSince.IsZero() {
+		l.pendingSince = at
+	}
+}
+
+func (l *link) Replied(reply commander.Reply) {
+	e := l.engine
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// a timeout leaves the command pending so the link loss rules keep counting
+	if reply.Err != nil {
+		l.checkLink(reply.ReceivedAt)
+		return
+	}
+	l.pendingSince = time.Time{}
+	l.checkLink(reply.ReceivedAt)
+
+	if reply.Decoded == nil {
+		return
+	}
+	if name, ok := commander.ErrorCodeName(reply.Raw[0]); ok && reply.Decoded.Layout == name {
+		return
+	}
+
+	l.evaluate(reply.Decoded, reply.ReceivedAt, func(rule Rule) bool {
+		return rule.opcode == reply.Opcode
+	})
+}
+
+// a streamed packet is checked against the rules of the command whose reply carries the same reading,
+// so a pressure limit holds in telemetry mode as well
+func (l *link) Packet(receivedAt time.Time, packet *commander.TelemetryPacket) {
+	l.engine.mu.Lock()
+	defer l.engine.mu.Unlock()
+
+	l.evaluate(packet.Decoded, receivedAt, func(rule Rule) bool {
+		return commander.TelemetryCarries(packet.Type, rule.opcode)
+	})
+}
+
+func (l *link) evaluate(decoded *commander.Decoded, at time.Time, applies func(rule Rule) bool) {
+	e := l.engine
+	for _, rule := range e.rules {
+		if rule.LinkLoss != 0 || !applies(rule) {
+			continue
+		}
+		for _, field := range decoded.Fields {
+			if field.Name != rule.Field {
+				continue
+			}
+			value, err := strconv.ParseFloat(field.Value, 64)
+			if err != nil {
+				continue
+			}
+			previous, hasPrevious := l.previous[rule.Name]
+			l.previous[rule.Name] = value
+			if tripped, message := rule.trips(value, previous, hasPrevious); tripped {
+				e.raise(rule, l.name, message, at)
+			} else {
+				e.normal(rule, l.name, at)
+			}
+		}
+	}
+}
+
+func (l *link) checkLink(now time.Time) {
+	e := l.engine
+	for _, rule := range e.rules {
+		if rule.LinkLoss == 0 {
+			continue
+		}
+		silent := time.Duration(0)
+		if !l.pendingSince.IsZero() {
+			silent = now.Sub(l.pendingSince)
+		}
+		if silent > rule.LinkLoss {
+			e.raise(rule, l.name, fmt.Sprintf("no reply for %s, limit %s", silent.Round(time.Second), rule.LinkLoss), now)
+		} else {
+			e.normal(rule, l.name, now)
+		}
+	}
+}
+
+func (e *Engine) find(rule string, link string) *Alarm {
+	for _, alarm := range e.alarms {
+		if alarm.Rule == rule && alarm.Link == link {
+			return alarm
+		}
+	}
+	return nil
+}
+
+func (e *Engine) raise(rule Rule, link string, message string, now time.Time) {
+	alarm := e.find(rule.Name, link)
+	if alarm != nil && alarm.Active {
+		alarm.Message = message
+		return
+	}
+
+	// a latched alarm that trips again needs a new acknowledgement
+	if alarm == nil {
+		alarm = &Alarm{Rule: rule.Name, Link: link}
+		e.alarms = append(e.alarms, alarm)
+	}
+	alarm.Message = message
+	alarm.Raised = now
+	alarm.Active = true
+	alarm.Acked = false
+
+	e.record(now, "RAISED", alarm)
+	e.log.Warn("Alarm raised", zap.String("rule", alarm.Rule), zap.String("link", link), zap.String("message", message))
+}
+
+func (e *Engine) normal(rule Rule, link string, now time.Time) {
+	alarm := e.find(rule.Name, link)
+	if alarm == nil || !alarm.Active {
+		return
+	}
+	alarm.Active = false
+	e.record(now, "NORMAL", alarm)
+	e.log.Info("Alarm returned to normal", zap.String("rule", alarm.Rule), zap.String("link", link))
+}
+
+func (e *Engine) record(now time.Time, event string, alarm *Alarm) {
+	fmt.Fprintf(e.logFile, "%s %-7s %s on %s: %s\n", now.Format(time.RFC3339), event, alarm.Rule, alarm.Link, alarm.Message)
+}
+
+// copies of every latched alarm in the order they were first raised
+func (e *Engine) Alarms() []Alarm {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	alarms := make([]Alarm, len(e.alarms))
+	for i, alarm := range e.alarms {
+		alarms[i] = *alarm
+	}
+	return alarms
+}
+
+func (e *Engine) Unacknowledged() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	count := 0
+	for _, alarm := range e.alarms {
+		if !alarm.Acked {
+			count++
+		}
+	}
+	return count
+}
+
+func (e *Engine) Acknowledge(rule string, link string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	alarm := e.find(rule, link)
+	if alarm == nil || alarm.Acked {
+		return false
+	}
+	alarm.Acked = true
+	e.record(time.Now(), "ACK", alarm)
+	e.log.Info("Alarm acknowledged", zap.String("rule", alarm.Rule), zap.String("link", link))
+	return true
+}
+
+func (e *Engine) AcknowledgeAll() {
+	for _, alarm := range e.Alarms() {
+		e.Acknowledge(alarm.Rule, alarm.Link)
+	}
+}
+
+// remove an acknowledged alarm once its condition is back to normal
+func (e *Engine) Clear(rule string, link string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	alarm := e.find(rule, link)
+	switch {
+	case alarm == nil:
+		return fmt.Errorf("no alarm for %s on %s", rule, link)
+	case alarm.Active:
+		return fmt.Errorf("%s is still active on %s", rule, link)
+	case !alarm.Acked:
+		return fmt.Errorf("%s on %s has to be acknowledged first", rule, link)
+	}
+
+	e.record(time.Now(), "CLEARED", alarm)
+	e.log.Info("Alarm cleared", zap.String("rule", alarm.Rule), zap.String("link", link))
+	for i, latched := range e.alarms {
+		if latched == alarm {
+			e.alarms = append(e.alarms[:i], e.alarms[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
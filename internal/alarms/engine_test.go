@@ -0,0 +1,175 @@
+package alarms
+
+import (
+	"UCLA-Rocket-Project/ILAYE/internal/commander"
+	"UCLA-Rocket-Project/ILAYE/internal/globals"
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const testRules = `
+rules:
+  - name: Fill line overpressure
+    command: CMD_GET_ANALOG_V1_PT_READING
+    field: Ch[1]
+    above: 800
+  - name: Analog V1 SD not growing
+    command: CMD_GET_ANALOG_V1_SD_UPDATE
+    field: FileSize
+    not_growing: true
+`
+
+func newTestEngine(t *testing.T) *Engine {
+	t.Helper()
+	dir := t.TempDir()
+	rulesPath := filepath.Join(dir, "alarms.yaml")
+	if err := os.WriteFile(rulesPath, []byte(testRules), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := LoadRules(rulesPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine, err := NewEngine(rules, filepath.Join(dir, "alarms.log"), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { engine.logFile.Close() })
+	return engine
+}
+
+func packed(t *testing.T, values ...any) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	for _, value := range values {
+		if err := binary.Write(&b, binary.LittleEndian, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return b.Bytes()
+}
+
+func ptReply(t *testing.T, ch [3]float32) commander.Reply {
+	raw := packed(t, ch)
+	decoded, err := commander.DecodeResponse(globals.CMD_GET_ANALOG_V1_PT_READING, raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	return commander.Reply{Opcode: globals.CMD_GET_ANALOG_V1_PT_READING, SentAt: now, ReceivedAt: now, Raw: raw, Decoded: decoded}
+}
+
+func ptPacket(t *testing.T, ch [3]float32) *commander.TelemetryPacket {
+	raw := packed(t, uint8(globals.TLM_ANALOG_PT), uint16(1), int64(0), ch)
+	packet, err := commander.DecodeTelemetry(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return packet
+}
+
+func latchedAlarms(engine *Engine) map[string]Alarm {
+	alarms := map[string]Alarm{}
+	for _, alarm := range engine.Alarms() {
+		alarms[alarm.Rule] = alarm
+	}
+	return alarms
+}
+
+func TestTelemetryPacketTripsAlarm(t *testing.T) {
+	tests := []struct {
+		name    string
+		ch      [3]float32
+		tripped bool
+	}{
+		{"below the limit", [3]float32{0, 799, 0}, false},
+		{"at the limit", [3]float32{0, 800, 0}, false},
+		{"above the limit", [3]float32{0, 801, 0}, true},
+		{"other channel above the limit", [3]float32{900, 10, 900}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			engine := newTestEngine(t)
+			engine.TelemetryLink("/dev/ttyUSB0").Packet(time.Now(), ptPacket(t, test.ch))
+
+			alarm, ok := latchedAlarms(engine)["Fill line overpressure"]
+			if ok != test.tripped || (ok && !alarm.Active) {
+				t.Fatalf("alarm raised %v, want %v", ok, test.tripped)
+			}
+		})
+	}
+}
+
+func TestTelemetryAndRepliesShareRuleState(t *testing.T) {
+	engine := newTestEngine(t)
+	engine.TelemetryLink("/dev/ttyUSB0").Packet(time.Now(), ptPacket(t, [3]float32{0, 900, 0}))
+	engine.Link("/dev/ttyUSB0").Replied(ptReply(t, [3]float32{0, 100, 0}))
+
+	alarm, ok := latchedAlarms(engine)["Fill line overpressure"]
+	if !ok || alarm.Active {
+		t.Fatalf("alarm should be latched and back to normal, got %+v", alarm)
+	}
+}
+
+func TestAlarmLatchAckClear(t *testing.T) {
+	// steps: "high" and "low" reply with Ch[1] above or below the limit, "ack" acknowledges
+	tests := []struct {
+		name     string
+		steps    []string
+		clearErr bool
+		latched  bool
+		acked    bool
+	}{
+		{"never tripped", []string{"low"}, true, false, false},
+		{"active alarm cannot be cleared", []string{"high", "ack"}, true, true, true},
+		{"unacknowledged alarm cannot be cleared", []string{"high", "low"}, true, true, false},
+		{"acknowledged and normal clears", []string{"high", "ack", "low"}, false, false, false},
+		{"tripping again needs a new acknowledgement", []string{"high", "ack", "low", "high"}, true, true, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			engine := newTestEngine(t)
+			link := engine.Link("/dev/ttyUSB0")
+			for _, step := range test.steps {
+				switch step {
+				case "high":
+					link.Replied(ptReply(t, [3]float32{0, 900, 0}))
+				case "low":
+					link.Replied(ptReply(t, [3]float32{0, 10, 0}))
+				case "ack":
+					engine.Acknowledge("Fill line overpressure", "/dev/ttyUSB0")
+				}
+			}
+
+			err := engine.Clear("Fill line overpressure", "/dev/ttyUSB0")
+			if (err != nil) != test.clearErr {
+				t.Fatalf("clear error %v, want error %v", err, test.clearErr)
+			}
+
+			alarm, ok := latchedAlarms(engine)["Fill line overpressure"]
+			if ok != test.latched {
+				t.Fatalf("latched %v, want %v", ok, test.latched)
+			}
+			if ok && alarm.Acked != test.acked {
+				t.Fatalf("acked %v, want %v", alarm.Acked, test.acked)
+			}
+		})
+	}
+}
+
+func TestLinksAreSeparate(t *testing.T) {
+	engine := newTestEngine(t)
+	engine.Link("/dev/ttyUSB0").Replied(ptReply(t, [3]float32{0, 900, 0}))
+	engine.Link("/dev/ttyUSB1").Replied(ptReply(t, [3]float32{0, 10, 0}))
+
+	alarms := engine.Alarms()
+	if len(alarms) != 1 || alarms[0].Link != "/dev/ttyUSB0" || !alarms[0].Active {
+		t.Fatalf("only the first link should alarm, got %+v", alarms)
+	}
+}
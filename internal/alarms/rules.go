@@ -0,0 +1,135 @@
+/**
+Alarm rules evaluated on every decoded reply
+
+Rules live in a YAML file (alarms.yaml by default) and watch either one field of
+a command's reply, named as in the raw inspector and the metrics, or the link:
+
+	rules:
+	  - name: Fill line overpressure
+	    command: CMD_GET_ANALOG_V1_PT_READING
+	    field: Ch[1]
+	    above: 800
+	  - name: Analog V1 SD not growing
+	    command: CMD_GET_ANALOG_V1_SD_UPDATE
+	    field: FileSize
+	    not_growing: true
+	  - name: Link lost
+	    link_loss: 30s
+
+An alarm latches when its rule trips and stays until the operator has acknowledged
+it and the condition has returned to normal, then it can be cleared.
+*/
+
+package alarms
+
+import (
+	"UCLA-Rocket-Project/ILAYE/internal/commander"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Rule struct {
+	Name string `yaml:"name"`
+
+	// a field of the decoded reply to command
+	Command    string   `yaml:"command"`
+	Field      string   `yaml:"field"`
+	Above      *float64 `yaml:"above"`
+	Below      *float64 `yaml:"below"`
+	NotGrowing bool     `yaml:"not_growing"`
+
+	// no reply for this long to a command that was sent
+	LinkLoss time.Duration `yaml:"link_loss"`
+
+	opcode byte
+}
+
+type ruleFile struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// load the rules, a missing file means no alarms, like a missing config file
+func LoadRules(path string) ([]Rule, error) {
+	contents, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Rule{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var file ruleFile
+	if err := yaml.Unmarshal(contents, &file); err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	for i := range file.Rules {
+		rule := &file.Rules[i]
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("%s: rule %d: %w", path, i+1, err)
+		}
+		if names[rule.Name] {
+			return nil, fmt.Errorf("%s: rule %d: duplicate name %q", path, i+1, rule.Name)
+		}
+		names[rule.Name] = true
+	}
+
+	return file.Rules, nil
+}
+
+func (r *Rule) validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("needs a name")
+	}
+
+	if r.LinkLoss != 0 {
+		if r.Command != "" || r.Field != "" || r.Above != nil || r.Below != nil || r.NotGrowing {
+			return fmt.Errorf("link_loss cannot be combined with a field condition")
+		}
+		return nil
+	}
+
+	opcode, ok := commander.OpcodeByName(r.Command)
+	if !ok {
+		return fmt.Errorf("unknown command %q, use a name from protocol/protocol.yaml", r.Command)
+	}
+	fields, ok := commander.ResponseFields(opcode)
+	if !ok {
+		return fmt.Errorf("%s has no reply to watch", r.Command)
+	}
+	if !slices.Contains(fields, r.Field) {
+		return fmt.Errorf("%s has no field %q, expected one of %s", r.Command, r.Field, strings.Join(fields, ", "))
+	}
+
+	conditions := 0
+	for _, set := range []bool{r.Above != nil, r.Below != nil, r.NotGrowing} {
+		if set {
+			conditions++
+		}
+	}
+	if conditions != 1 {
+		return fmt.Errorf("needs exactly one of above, below, not_growing or link_loss")
+	}
+
+	r.opcode = opcode
+	return nil
+}
+
+// whether value trips the rule, and a description of why for the banner and the alarm log
+func (r Rule) trips(value float64, previous float64, hasPrevious bool) (bool, string) {
+	switch {
+	case r.Above != nil:
+		return value > *r.Above, fmt.Sprintf("%s = %g, limit above %g", r.Field, value, *r.Above)
+	case r.Below != nil:
+		return value < *r.Below, fmt.Sprintf("%s = %g, limit below %g", r.Field, value, *r.Below)
+	case r.NotGrowing:
+		return hasPrevious && value <= previous, fmt.Sprintf("%s = %g, was %g", r.Field, value, previous)
+	}
+	return false, ""
+}
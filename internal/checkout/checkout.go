@@ -0,0 +1,438 @@
+/**
+Everything that can be run against a rocket section, shared by the TUI and the procedure runner
+
+A checkout binds a connection, the operator config and the selected section, and maps
+the opcodes listed in the menus onto the commander functions that implement them.
+*/
+
+package checkout
+
+import (
+	"UCLA-Rocket-Project/ILAYE/internal/commander"
+	"UCLA-Rocket-Project/ILAYE/internal/config"
+	"UCLA-Rocket-Project/ILAYE/internal/globals"
+	"fmt"
+	"io"
+)
+
+const FILLER_WHITESPACE = 0xFE
+
+type Section int
+
+const (
+	SECTION_NOSE_CONE Section = iota
+	SECTION_BODY_TUBE
+)
+
+func (s Section) String() string {
+	if s == SECTION_NOSE_CONE {
+		return "Nose Cone"
+	}
+	return "Body Tube"
+}
+
+// the section as it is written in procedure files and the API, nose_cone or body_tube
+func ParseSection(name string) (Section, error) {
+	switch name {
+	case "nose_cone":
+		return SECTION_NOSE_CONE, nil
+	case "body_tube":
+		return SECTION_BODY_TUBE, nil
+	}
+	return 0, fmt.Errorf("unknown section %q, expected nose_cone or body_tube", name)
+}
+
+type CommandAndDesc struct {
+	CommandName string
+	OpCode      byte
+}
+
+// Nose Cone tests
+var noseConeTests []CommandAndDesc = []CommandAndDesc{
+	{"Select All", 0xFF},
+	{"--- GENERAL ---", FILLER_WHITESPACE},
+	{"Test uplinker serial connection", globals.CMD_TEST_SERIAL_CONN},
+	{"Measure clock drift (takes several minutes)", globals.HOST_CLOCK_DRIFT_TEST},
+	{"Check board timestamps", globals.HOST_TIMESTAMP_CHECK},
+	{"Plan SD card capacity (after SD clear and SD checks)", globals.HOST_SD_CAPACITY_PLAN},
+	{"--- RADIO ---", FILLER_WHITESPACE},
+	{"Get Radio SD Card Update", globals.CMD_GET_RADIO_SD_UPDATE},
+	{"--- DIGITAL V2 ---", FILLER_WHITESPACE},
+	{"Get Digital V2 SD Card Update", globals.CMD_GET_DIGITAL_V2_SD_UPDATE},
+	{"Get Digital V2 Altimeter Reading", globals.CMD_GET_DIGITAL_V2_ALTIMETER_READING},
+	{"Get Digital V2 Shock 1 Reading", globals.CMD_GET_DIGITAL_V2_SHOCK_1_READING},
+	{"Get Digital V2 Shock 2 Reading", globals.CMD_GET_DIGITAL_V2_SHOCK_2_READING},
+	{"Get Digital V2 IMU Reading", globals.CMD_GET_DIGITAL_V2_IMU_READING},
+	{"Get Digital V2 GPS Reading", globals.CMD_GET_DIGITAL_V2_GPS_READING},
+	{"Cross-check Digital V2 Shock 1, Shock 2 and IMU", globals.HOST_SHOCK_CROSS_CHECK},
+}
+
+// Body Tube tests
+var bodyTubeTests []CommandAndDesc = []CommandAndDesc{
+	{"Select All", 0xFF},
+	{"--- GENERAL ---", FILLER_WHITESPACE},
+	{"Test uplinker serial connection", globals.CMD_TEST_SERIAL_CONN},
+	{"Measure clock drift (takes several minutes)", globals.HOST_CLOCK_DRIFT_TEST},
+	{"Check board timestamps", globals.HOST_TIMESTAMP_CHECK},
+	{"Plan SD card capacity (after SD clear and SD checks)", globals.HOST_SD_CAPACITY_PLAN},
+	{"--- RADIO ---", FILLER_WHITESPACE},
+	{"Get Radio SD Card Update", globals.CMD_GET_RADIO_SD_UPDATE},
+	{"--- ANALOG V1 ---", FILLER_WHITESPACE},
+	{"Get Analog V1 SD Card Update", globals.CMD_GET_ANALOG_V1_SD_UPDATE},
+	{"Get Analog V1 PT Reading", globals.CMD_GET_ANALOG_V1_PT_READING},
+	{"--- ANALOG V2 ---", FILLER_WHITESPACE},
+	{"Get Analog V2 SD Card Update", globals.CMD_GET_ANALOG_V2_SD_UPDATE},
+	{"Get Analog V2 PT Reading", globals.CMD_GET_ANALOG_V2_PT_READING},
+	{"--- DIGITAL V1 ---", FILLER_WHITESPACE},
+	{"Get Digital V1 SD Card Update", globals.CMD_GET_DIGITAL_V1_SD_UPDATE},
+	{"Get Digital V1 Altimeter Reading", globals.CMD_GET_DIGITAL_V1_ALTIMETER_READING},
+	{"Get Digital V1 Shock 1 Reading", globals.CMD_GET_DIGITAL_V1_SHOCK_1_READING},
+	{"Get Digital V1 IMU Reading", globals.CMD_GET_DIGITAL_V1_IMU_READING},
+}
+
+// Nose Cone commands
+var noseConeCommands []CommandAndDesc = []CommandAndDesc{
+	{"Select All", 0xFF},
+	{"--- GENERAL ---", FILLER_WHITESPACE},
+	{"Enter Normal Mode", globals.CMD_ENTER_NORMAL},
+	{"Enter Inspect Mode", globals.CMD_ENTER_INSPECT},
+	{"Jump Clock", globals.CMD_JUMP_CLK},
+	{"Prepare for launch (No coming back!)", globals.CMD_ENTER_LAUNCH_MODE},
+	{"--- RADIO ---", FILLER_WHITESPACE},
+	{"Clear Radio SD", globals.CMD_CLEAR_RADIO_SD},
+	{"--- DIGITAL V2 ---", FILLER_WHITESPACE},
+	{"Clear Digital V2 SD", globals.CMD_CLEAR_DIGITAL_V2_SD},
+	{"Zero Digital V2 Altimeter", globals.HOST_ZERO_DIGITAL_V2_ALTIMETER},
+}
+
+// Body Tube commands
+var bodyTubeCommands []CommandAndDesc = []CommandAndDesc{
+	{"Select All", 0xFF},
+	{"--- GENERAL ---", FILLER_WHITESPACE},
+	{"Enter Normal Mode", globals.CMD_ENTER_NORMAL},
+	{"Enter Inspect Mode", globals.CMD_ENTER_INSPECT},
+	{"Jump Clock", globals.CMD_JUMP_CLK},
+	{"Prepare for launch (No coming back!)", globals.CMD_ENTER_LAUNCH_MODE},
+	{"--- RADIO ---", FILLER_WHITESPACE},
+	{"Clear Radio SD", globals.CMD_CLEAR_RADIO_SD},
+	{"--- ANALOG V1 ---", FILLER_WHITESPACE},
+	{"Clear Analog V1 SD", globals.CMD_CLEAR_ANALOG_V1_SD},
+	{"--- ANALOG V2 ---", FILLER_WHITESPACE},
+	{"Clear Analog V2 SD", globals.CMD_CLEAR_ANALOG_V2_SD},
+	{"--- DIGITAL V1 ---", FILLER_WHITESPACE},
+	{"Clear Digital V1 SD", globals.CMD_CLEAR_DIGITAL_V1_SD},
+	{"Zero Digital V1 Altimeter", globals.HOST_ZERO_DIGITAL_V1_ALTIMETER},
+}
+
+// tests that take minutes are picked one by one, select all leaves them out
+func LongTest(opCode byte) bool {
+	return opCode == globals.HOST_CLOCK_DRIFT_TEST
+}
+
+// Boards of each section, keyed on the SD update every one of them answers
+var noseConeBoards []CommandAndDesc = []CommandAndDesc{
+	{"Radio", globals.CMD_GET_RADIO_SD_UPDATE},
+	{"Digital V2", globals.CMD_GET_DIGITAL_V2_SD_UPDATE},
+}
+
+var bodyTubeBoards []CommandAndDesc = []CommandAndDesc{
+	{"Radio", globals.CMD_GET_RADIO_SD_UPDATE},
+	{"Analog V1", globals.CMD_GET_ANALOG_V1_SD_UPDATE},
+	{"Analog V2", globals.CMD_GET_ANALOG_V2_SD_UPDATE},
+	{"Digital V1", globals.CMD_GET_DIGITAL_V1_SD_UPDATE},
+}
+
+// Replies carrying board timestamps that are cross checked by the timing test and followed by the drift test
+var noseConeTimingSources []commander.TimingSource = []commander.TimingSource{
+	{Name: "Radio SD", Command: globals.CMD_GET_RADIO_SD_UPDATE, Kind: commander.TIMESTAMP_SD_UPDATE},
+	{Name: "Digital V2 SD", Command: globals.CMD_GET_DIGITAL_V2_SD_UPDATE, Kind: commander.TIMESTAMP_SD_UPDATE},
+	{Name: "Digital V2 IMU", Command: globals.CMD_GET_DIGITAL_V2_IMU_READING, Kind: commander.TIMESTAMP_IMU},
+	{Name: "Digital V2 Altimeter", Command: globals.CMD_GET_DIGITAL_V2_ALTIMETER_READING, Kind: commander.TIMESTAMP_ALTIMETER},
+}
+
+var bodyTubeTimingSources []commander.TimingSource = []commander.TimingSource{
+	{Name: "Radio SD", Command: globals.CMD_GET_RADIO_SD_UPDATE, Kind: commander.TIMESTAMP_SD_UPDATE},
+	{Name: "Analog V1 SD", Command: globals.CMD_GET_ANALOG_V1_SD_UPDATE, Kind: commander.TIMESTAMP_SD_UPDATE},
+	{Name: "Analog V2 SD", Command: globals.CMD_GET_ANALOG_V2_SD_UPDATE, Kind: commander.TIMESTAMP_SD_UPDATE},
+	{Name: "Digital V1 SD", Command: globals.CMD_GET_DIGITAL_V1_SD_UPDATE, Kind: commander.TIMESTAMP_SD_UPDATE},
+	{Name: "Digital V1 IMU", Command: globals.CMD_GET_DIGITAL_V1_IMU_READING, Kind: commander.TIMESTAMP_IMU},
+	{Name: "Digital V1 Altimeter", Command: globals.CMD_GET_DIGITAL_V1_ALTIMETER_READING, Kind: commander.TIMESTAMP_ALTIMETER},
+}
+
+type Checkout struct {
+	Conn    commander.SerialReaderWriter
+	Config  *config.Config
+	Section Section
+
+	// boards reported by the version handshake, nil shows every V1 and V2 entry
+	Versions *commander.BoardVersions
+}
+
+// Helper to get the active test list based on selected section
+func (c Checkout) Tests() []CommandAndDesc {
+	if c.Section == SECTION_NOSE_CONE {
+		return c.fitted(noseConeTests)
+	}
+	return c.fitted(bodyTubeTests)
+}
+
+// Helper to get the active command list based on selected section
+func (c Checkout) Commands() []CommandAndDesc {
+	if c.Section == SECTION_NOSE_CONE {
+		return c.fitted(noseConeCommands)
+	}
+	return c.fitted(bodyTubeCommands)
+}
+
+// whether the board revision an opcode needs was reported by the handshake
+func (c Checkout) supports(opCode byte) bool {
+	return c.Versions == nil || c.Versions.Supports(opCode)
+}
+
+// drop the entries for board revisions that are not fitted, and any header left without entries
+func (c Checkout) fitted(entries []CommandAndDesc) []CommandAndDesc {
+	if c.Versions == nil {
+		return entries
+	}
+
+	kept := []CommandAndDesc{}
+	for i, entry := range entries {
+		if i == 0 {
+			kept = append(kept, entry)
+			continue
+		}
+		if entry.OpCode == FILLER_WHITESPACE {
+			// headers are kept for now and removed below if nothing follows them
+			kept = append(kept, entry)
+			continue
+		}
+		if c.supports(entry.OpCode) {
+			kept = append(kept, entry)
+		}
+	}
+
+	result := []CommandAndDesc{}
+	for i, entry := range kept {
+		if i > 0 && entry.OpCode == FILLER_WHITESPACE && (i+1 == len(kept) || kept[i+1].OpCode == FILLER_WHITESPACE) {
+			continue
+		}
+		result = append(result, entry)
+	}
+	return result
+}
+
+// look up a test or command by the name shown in the menus
+func find(entries []CommandAndDesc, name string) (CommandAndDesc, bool) {
+	for i, entry := range entries {
+		if i == 0 || entry.OpCode == FILLER_WHITESPACE {
+			continue
+		}
+		if entry.CommandName == name {
+			return entry, true
+		}
+	}
+	return CommandAndDesc{}, false
+}
+
+func (c Checkout) FindTest(name string) (CommandAndDesc, bool) {
+	return find(c.Tests(), name)
+}
+
+func (c Checkout) FindCommand(name string) (CommandAndDesc, bool) {
+	return find(c.Commands(), name)
+}
+
+// Helper to get the timestamped replies of the selected section
+func (c Checkout) timingSources() []commander.TimingSource {
+	sources := bodyTubeTimingSources
+	if c.Section == SECTION_NOSE_CONE {
+		sources = noseConeTimingSources
+	}
+
+	fitted := []commander.TimingSource{}
+	for _, source := range sources {
+		if c.supports(source.Command) {
+			fitted = append(fitted, source)
+		}
+	}
+	return fitted
+}
+
+// Helper to get the names of the boards in the selected section
+func (c Checkout) Boards() []string {
+	sectionBoards := bodyTubeBoards
+	if c.Section == SECTION_NOSE_CONE {
+		sectionBoards = noseConeBoards
+	}
+
+	boards := []string{}
+	for _, board := range sectionBoards {
+		if c.supports(board.OpCode) {
+			boards = append(boards, board.CommandName)
+		}
+	}
+	return boards
+}
+
+// Helper to get the config of the selected section
+func (c Checkout) sectionConfig() config.SectionConfig {
+	if c.Section == SECTION_NOSE_CONE {
+		return c.Config.Sections.NoseCone
+	}
+	return c.Config.Sections.BodyTube
+}
+
+// Helper to get what the IMU of the selected section should report on the rail
+func (c Checkout) imuExpectation() commander.IMUExpectation {
+	imu := c.Config.IMU
+	orientation := c.sectionConfig().IMU
+	return commander.IMUExpectation{
+		Gravity:          imu.Gravity,
+		GravityTolerance: imu.GravityTolerance,
+		MaxGyroBias:      imu.MaxGyroBias,
+		Samples:          imu.Samples,
+		UpAxis:           orientation.UpAxis,
+		ToleranceDeg:     orientation.ToleranceDeg,
+	}
+}
+
+// Helper to get how the shock accelerometers of the selected section are mounted
+func (c Checkout) shockCrossCheck() commander.ShockCrossCheck {
+	shock := c.sectionConfig().Shock
+	return commander.ShockCrossCheck{
+		Shock1:        commander.SensorMounting(shock.Shock1),
+		Shock2:        commander.SensorMounting(shock.Shock2),
+		DeadThreshold: shock.DeadThreshold,
+		Tolerance:     shock.Tolerance,
+	}
+}
+
+// Helper to get the SD logging expected from a board
+func (c Checkout) sdExpectation(boardType string) commander.SDExpectation {
+	sd := c.Config.SD
+	return commander.SDExpectation{
+		Window:             sd.TestWindow,
+		MinBytesPerSec:     sd.Boards[boardType].MinBytesPerSec,
+		TimestampTolerance: sd.TimestampTolerance,
+		Mission:            commander.MissionProfile(c.Config.Mission),
+	}
+}
+
+// Map a test opCode to its commander function
+func (c Checkout) RunTest(w io.Writer, opCode byte) bool {
+	conn := c.Conn
+	switch opCode {
+	case globals.CMD_TEST_SERIAL_CONN:
+		return commander.TestSerialConnection(conn, w)
+	case globals.HOST_CLOCK_DRIFT_TEST:
+		clock := c.Config.Clock
+		return commander.MeasureClockDrift(conn, w, c.timingSources(), clock.MaxOffset, clock.DriftDuration, clock.DriftInterval, clock.MaxDriftPPM)
+	case globals.HOST_SD_CAPACITY_PLAN:
+		return commander.PlanSDCapacity(conn, w, c.Boards(), commander.MissionProfile(c.Config.Mission))
+	case globals.HOST_TIMESTAMP_CHECK:
+		return commander.CheckBoardTimestamps(conn, w, c.timingSources(), c.Config.Clock.TimestampTolerance)
+	case globals.CMD_GET_ANALOG_V1_SD_UPDATE:
+		return commander.InspectSDCards(conn, w, "Analog V1", globals.CMD_GET_ANALOG_V1_SD_UPDATE, false, c.sdExpectation("Analog V1"))
+	case globals.CMD_GET_ANALOG_V1_PT_READING:
+		return commander.CheckAnalogPTCommand(conn, w, "Analog V1", globals.CMD_GET_ANALOG_V1_PT_READING)
+	case globals.CMD_GET_ANALOG_V2_SD_UPDATE:
+		return commander.InspectSDCards(conn, w, "Analog V2", globals.CMD_GET_ANALOG_V2_SD_UPDATE, false, c.sdExpectation("Analog V2"))
+	case globals.CMD_GET_ANALOG_V2_PT_READING:
+		return commander.CheckAnalogPTCommand(conn, w, "Analog V2", globals.CMD_GET_ANALOG_V2_PT_READING)
+	case globals.CMD_GET_DIGITAL_V1_SD_UPDATE:
+		return commander.InspectSDCards(conn, w, "Digital V1", globals.CMD_GET_DIGITAL_V1_SD_UPDATE, false, c.sdExpectation("Digital V1"))
+	case globals.CMD_GET_DIGITAL_V1_SHOCK_1_READING:
+		return commander.CheckDigitalShockCmd(conn, w, "V1", globals.CMD_GET_DIGITAL_V1_SHOCK_1_READING)
+	case globals.CMD_GET_DIGITAL_V2_SHOCK_1_READING:
+		return commander.CheckDigitalShockCmd(conn, w, "V2", globals.CMD_GET_DIGITAL_V2_SHOCK_1_READING)
+	case globals.CMD_GET_DIGITAL_V2_SHOCK_2_READING:
+		return commander.CheckDigitalShockCmd(conn, w, "V2", globals.CMD_GET_DIGITAL_V2_SHOCK_2_READING)
+	case globals.HOST_SHOCK_CROSS_CHECK:
+		return commander.CrossCheckShockAccels(conn, w, "V2", globals.CMD_GET_DIGITAL_V2_SHOCK_1_READING, globals.CMD_GET_DIGITAL_V2_SHOCK_2_READING, globals.CMD_GET_DIGITAL_V2_IMU_READING, c.shockCrossCheck())
+	case globals.CMD_GET_DIGITAL_V1_IMU_READING:
+		return commander.CheckDigitalIMUCommand(conn, w, "V1", globals.CMD_GET_DIGITAL_V1_IMU_READING, c.imuExpectation())
+	case globals.CMD_GET_DIGITAL_V2_IMU_READING:
+		return commander.CheckDigitalIMUCommand(conn, w, "V2", globals.CMD_GET_DIGITAL_V2_IMU_READING, c.imuExpectation())
+	case globals.CMD_GET_DIGITAL_V1_ALTIMETER_READING:
+		return commander.CheckDigitalAltimeterCommand(conn, w, "V1", globals.CMD_GET_DIGITAL_V1_ALTIMETER_READING, c.Config.Altimeter.GroundPressurePa)
+	case globals.CMD_GET_DIGITAL_V2_ALTIMETER_READING:
+		return commander.CheckDigitalAltimeterCommand(conn, w, "V2", globals.CMD_GET_DIGITAL_V2_ALTIMETER_READING, c.Config.Altimeter.GroundPressurePa)
+	case globals.CMD_GET_DIGITAL_V2_GPS_READING:
+		return commander.CheckDigitalGPSCommand(conn, w, "V2", globals.CMD_GET_DIGITAL_V2_GPS_READING, commander.LaunchSiteBounds(c.Config.GPS.LaunchSite), c.Config.GPS.ExportDir)
+	case globals.CMD_GET_DIGITAL_V2_SD_UPDATE:
+		return commander.InspectSDCards(conn, w, "Digital V2", globals.CMD_GET_DIGITAL_V2_SD_UPDATE, false, c.sdExpectation("Digital V2"))
+	case globals.CMD_GET_RADIO_SD_UPDATE:
+		return commander.InspectSDCards(conn, w, "Radio", globals.CMD_GET_RADIO_SD_UPDATE, true, c.sdExpectation("Radio"))
+	}
+
+	return false
+}
+
+// Map a command opCode to its commander function
+func (c Checkout) RunCommand(w io.Writer, opCode byte) bool {
+	conn := c.Conn
+	switch opCode {
+	case globals.CMD_ENTER_NORMAL:
+		return commander.EnterNormalCommand(conn, w)
+	case globals.CMD_ENTER_INSPECT:
+		return commander.EnterInspectCommand(conn, w)
+	case globals.CMD_CLEAR_ANALOG_V1_SD:
+		return commander.ClearSDCard(conn, w, "Analog V1", globals.CMD_CLEAR_ANALOG_V1_SD)
+	case globals.CMD_CLEAR_DIGITAL_V1_SD:
+		return commander.ClearSDCard(conn, w, "Digital V1", globals.CMD_CLEAR_DIGITAL_V1_SD)
+	case globals.CMD_CLEAR_ANALOG_V2_SD:
+		return commander.ClearSDCard(conn, w, "Analog V2", globals.CMD_CLEAR_ANALOG_V2_SD)
+	case globals.CMD_CLEAR_DIGITAL_V2_SD:
+		return commander.ClearSDCard(conn, w, "Digital V2", globals.CMD_CLEAR_DIGITAL_V2_SD)
+	case globals.CMD_CLEAR_RADIO_SD:
+		return commander.ClearSDCard(conn, w, "Radio", globals.CMD_CLEAR_RADIO_SD)
+	case globals.HOST_ZERO_DIGITAL_V1_ALTIMETER:
+		return commander.ZeroAltimeter(conn, w, "V1", globals.CMD_GET_DIGITAL_V1_ALTIMETER_READING)
+	case globals.HOST_ZERO_DIGITAL_V2_ALTIMETER:
+		return commander.ZeroAltimeter(conn, w, "V2", globals.CMD_GET_DIGITAL_V2_ALTIMETER_READING)
+	case globals.CMD_JUMP_CLK:
+		return commander.JumpClocks(conn, w, c.Config.Clock.MaxOffset)
+	case globals.CMD_ENTER_LAUNCH_MODE:
+		return commander.EnterLaunchMode(conn, w)
+	}
+
+	return false
+}
+
+// query the board versions on connect, nil versions with ok means the radio did not
+// answer and the menus stay unfiltered, !ok means ILAYE must not talk to this radio
+func Handshake(conn commander.SerialReaderWriter, log io.Writer, cfg *config.Config) (*commander.BoardVersions, bool) {
+	versions := commander.QueryVersions(conn, log)
+	if versions == nil {
+		if cfg.Versions.RequireHandshake {
+			fmt.Fprintf(log, "[Version]: Radio versions are unknown and the handshake is required\n")
+			return nil, false
+		}
+		fmt.Fprintf(log, "[Version]: WARNING radio versions are unknown, showing every V1 and V2 entry\n")
+		return nil, true
+	}
+
+	required := commander.VersionRequirements{}
+	minimums := []struct {
+		board string
+		text  string
+		out   *commander.FirmwareVersion
+	}{
+		{"radio", cfg.Versions.MinFirmware.Radio, &required.Radio},
+		{"analog", cfg.Versions.MinFirmware.Analog, &required.Analog},
+		{"digital", cfg.Versions.MinFirmware.Digital, &required.Digital},
+	}
+	for _, minimum := range minimums {
+		version, err := commander.ParseFirmwareVersion(minimum.text)
+		if err != nil {
+			fmt.Fprintf(log, "[Version]: WARNING ignoring minimum %s firmware: %v\n", minimum.board, err)
+			continue
+		}
+		*minimum.out = version
+	}
+
+	if !versions.Check(log, required) {
+		return versions, false
+	}
+	return versions, true
+}
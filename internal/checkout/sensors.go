@@ -0,0 +1,95 @@
+package checkout
+
+import (
+	"UCLA-Rocket-Project/ILAYE/internal/commander"
+	"UCLA-Rocket-Project/ILAYE/internal/globals"
+	"io"
+	"sort"
+)
+
+type sampler func(c Checkout, w io.Writer) (any, bool)
+
+// wrap a commander sample function so a nil pointer reads as a failed sample
+func sampled[T any](reading *T) (any, bool) {
+	if reading == nil {
+		return nil, false
+	}
+	return reading, true
+}
+
+// sensors that can be sampled by name, e.g. from a procedure file
+var sensors = map[string]sampler{
+	"Analog V1 PT": func(c Checkout, w io.Writer) (any, bool) {
+		return sampled(commander.SamplePT(c.Conn, w, "Analog V1", globals.CMD_GET_ANALOG_V1_PT_READING))
+	},
+	"Analog V2 PT": func(c Checkout, w io.Writer) (any, bool) {
+		return sampled(commander.SamplePT(c.Conn, w, "Analog V2", globals.CMD_GET_ANALOG_V2_PT_READING))
+	},
+	"Digital V1 Altimeter": func(c Checkout, w io.Writer) (any, bool) {
+		return sampled(commander.SampleAltimeter(c.Conn, w, "V1", globals.CMD_GET_DIGITAL_V1_ALTIMETER_READING, c.Config.Altimeter.GroundPressurePa))
+	},
+	"Digital V2 Altimeter": func(c Checkout, w io.Writer) (any, bool) {
+		return sampled(commander.SampleAltimeter(c.Conn, w, "V2", globals.CMD_GET_DIGITAL_V2_ALTIMETER_READING, c.Config.Altimeter.GroundPressurePa))
+	},
+	"Digital V2 GPS": func(c Checkout, w io.Writer) (any, bool) {
+		return sampled(commander.SampleGPS(c.Conn, w, "V2", globals.CMD_GET_DIGITAL_V2_GPS_READING))
+	},
+	"Digital V1 IMU": func(c Checkout, w io.Writer) (any, bool) {
+		return sampled(commander.SampleIMU(c.Conn, w, "V1", globals.CMD_GET_DIGITAL_V1_IMU_READING))
+	},
+	"Digital V2 IMU": func(c Checkout, w io.Writer) (any, bool) {
+		return sampled(commander.SampleIMU(c.Conn, w, "V2", globals.CMD_GET_DIGITAL_V2_IMU_READING))
+	},
+	"Digital V1 Shock 1": func(c Checkout, w io.Writer) (any, bool) {
+		return sampled(commander.SampleShock(c.Conn, w, "V1", globals.CMD_GET_DIGITAL_V1_SHOCK_1_READING))
+	},
+	"Digital V2 Shock 1": func(c Checkout, w io.Writer) (any, bool) {
+		return sampled(commander.SampleShock(c.Conn, w, "V2", globals.CMD_GET_DIGITAL_V2_SHOCK_1_READING))
+	},
+	"Digital V2 Shock 2": func(c Checkout, w io.Writer) (any, bool) {
+		return sampled(commander.SampleShock(c.Conn, w, "V2", globals.CMD_GET_DIGITAL_V2_SHOCK_2_READING))
+	},
+	"Radio SD": func(c Checkout, w io.Writer) (any, bool) {
+		return sampled(commander.SampleSD(c.Conn, w, globals.CMD_GET_RADIO_SD_UPDATE))
+	},
+	"Analog V1 SD": func(c Checkout, w io.Writer) (any, bool) {
+		return sampled(commander.SampleSD(c.Conn, w, globals.CMD_GET_ANALOG_V1_SD_UPDATE))
+	},
+	"Analog V2 SD": func(c Checkout, w io.Writer) (any, bool) {
+		return sampled(commander.SampleSD(c.Conn, w, globals.CMD_GET_ANALOG_V2_SD_UPDATE))
+	},
+	"Digital V1 SD": func(c Checkout, w io.Writer) (any, bool) {
+		return sampled(commander.SampleSD(c.Conn, w, globals.CMD_GET_DIGITAL_V1_SD_UPDATE))
+	},
+	"Digital V2 SD": func(c Checkout, w io.Writer) (any, bool) {
+		return sampled(commander.SampleSD(c.Conn, w, globals.CMD_GET_DIGITAL_V2_SD_UPDATE))
+	},
+}
+
+func SensorNames() []string {
+	names := make([]string, 0, len(sensors))
+	for name := range sensors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func IsSensor(name string) bool {
+	_, ok := sensors[name]
+	return ok
+}
+
+// take one reading from a sensor and flatten it into field name -> value
+func (c Checkout) Sample(w io.Writer, sensor string) (map[string]float64, bool) {
+	sample, ok := sensors[sensor]
+	if !ok {
+		return nil, false
+	}
+
+	reading, ok := sample(c, w)
+	if !ok {
+		return nil, false
+	}
+	return commander.Fields(reading), true
+}
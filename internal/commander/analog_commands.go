@@ -1,8 +1,6 @@
 package commander
 
 import (
-	"bytes"
-	"encoding/binary"
 	"fmt"
 	"io"
 )
@@ -35,42 +33,48 @@ import (
 // 	return true
 // }
 
-type ptUpdate struct {
-	Ch [3]float32
+// PT channels after calibration
+type PTReading struct {
+	Ch [3]float64
 }
 
-func CheckAnalogPTCommand(conn SerialReaderWriter, log io.Writer, boardType string, command byte) bool {
-	fmt.Fprintf(log, "[Check %s PTs]: Entering inspect mode\n", boardType)
+func SamplePT(conn SerialReaderWriter, log io.Writer, boardType string, command byte) *PTReading {
+	tag := fmt.Sprintf("Check %s PTs", boardType)
+
+	fmt.Fprintf(log, "[%s]: Entering inspect mode\n", tag)
 	if !EnterInspectCommand(conn, log) {
-		fmt.Fprintf(log, "[Check %s PTs]: Failed to enter inspect mode\n", boardType)
-		return false
+		fmt.Fprintf(log, "[%s]: Failed to enter inspect mode\n", tag)
+		return nil
 	}
 
-	ptRequestMessage := getDispatchCommand(command)
-	conn.WriteSingleMessage(ptRequestMessage[:], COMMAND_SEQUENCE_SIZE)
-	fmt.Fprintf(log, "[Chceck %s PTs]: Sent command requesting PTs update\n", boardType)
+	fmt.Fprintf(log, "[%s]: Sent command requesting PTs update\n", tag)
+	var updateData ptUpdate
+	if _, ok := requestReading(conn, log, tag, command, &updateData); !ok {
+		return nil
+	}
+	fmt.Fprintf(log, "[%s]: Receieved response from boards\n", tag)
 
-	res, err := conn.ReadSingleOrTimeout()
-	if err != nil {
-		fmt.Fprintf(log, "[Check %s PTs]: Read timed out", boardType)
-		return false
+	// the PTs are not calibrated on the host yet, so the raw reading is passed through
+	reading := &PTReading{}
+	for i, raw := range updateData.Ch {
+		reading.Ch[i] = float64(raw)
 	}
-	fmt.Fprintf(log, "[Check %s PTs]: Receieved response from boards\n", boardType)
-	streamReader := bytes.NewReader(res[:])
-	var updateData ptUpdate
-	if err := binary.Read(streamReader, binary.LittleEndian, &updateData); err != nil {
+	return reading
+}
+
+func CheckAnalogPTCommand(conn SerialReaderWriter, log io.Writer, boardType string, command byte) bool {
+	reading := SamplePT(conn, log, boardType, command)
+	if reading == nil {
 		return false
 	}
+
 	fmt.Fprintf(
 		log,
-		"[Check %s PTs]: PT raw readings: %f %f %f\nCalibrated readings: %f %f %f\n",
+		"[Check %s PTs]: Raw readings: %f %f %f\n",
 		boardType,
-		updateData.Ch[0],
-		updateData.Ch[1],
-		updateData.Ch[2],
-		updateData.Ch[0],
-		updateData.Ch[1],
-		updateData.Ch[2],
+		reading.Ch[0],
+		reading.Ch[1],
+		reading.Ch[2],
 	)
 
 	return true
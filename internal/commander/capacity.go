@@ -0,0 +1,78 @@
+package commander
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// how long the vehicle waits on the pad and flies, boards log faster once launch mode drops their delays
+type MissionProfile struct {
+	PadWait time.Duration
+	Flight  time.Duration
+
+	// flight logging rate as a multiple of the pad rate, 0 is treated as 1
+	FlightRateMultiplier float64
+}
+
+func (p MissionProfile) String() string {
+	return fmt.Sprintf("%s pad wait + %s flight", p.PadWait, p.Flight)
+}
+
+// pad wait that fits on the card once the flight has been reserved, negative when the flight alone does not fit
+func (p MissionProfile) padWaitCapacity(freeMB uint32, bytesPerSec float64) time.Duration {
+	multiplier := p.FlightRateMultiplier
+	if multiplier == 0 {
+		multiplier = 1
+	}
+
+	freeBytes := float64(freeMB) * 1e6
+	flightBytes := bytesPerSec * multiplier * p.Flight.Seconds()
+	return time.Duration((freeBytes - flightBytes) / bytesPerSec * float64(time.Second))
+}
+
+// combine the free space from the last SD clear with the rate from the last SD check, per board
+func PlanSDCapacity(conn SerialReaderWriter, log io.Writer, boards []string, profile MissionProfile) bool {
+	fmt.Fprintf(log, "[SD Capacity]: Planning for %s\n", profile)
+
+	var freeSpace map[string]uint32
+	var rates map[string]float64
+	withSession(conn, func(s *sessionState) {
+		freeSpace = make(map[string]uint32, len(s.sdFreeMB))
+		for board, free := range s.sdFreeMB {
+			freeSpace[board] = free
+		}
+		rates = make(map[string]float64, len(s.sdBytesPerSec))
+		for board, rate := range s.sdBytesPerSec {
+			rates[board] = rate
+		}
+	})
+
+	success := true
+	for _, board := range boards {
+		free, knownFree := freeSpace[board]
+		rate, knownRate := rates[board]
+		if knownRate && rate == 0 {
+			// only recorded for boards that are allowed to stop logging, they need no space
+			fmt.Fprintf(log, "[SD Capacity]: %s: not logging, nothing to plan\n", board)
+			continue
+		}
+		if !knownFree || !knownRate || rate < 0 {
+			fmt.Fprintf(log, "[SD Capacity]: %s: WARNING unknown, clear its SD card and run its SD card check first\n", board)
+			success = false
+			continue
+		}
+
+		padWait := profile.padWaitCapacity(free, rate)
+		fmt.Fprintf(
+			log, "[SD Capacity]: %s: %d MB free at %.0f B/s, %.1f h of pad wait plus the flight\n",
+			board, free, rate, padWait.Hours(),
+		)
+		if padWait < profile.PadWait {
+			fmt.Fprintf(log, "[SD Capacity]: %s: WARNING short of the %s pad wait by %s\n", board, profile.PadWait, (profile.PadWait - padWait).Round(time.Minute))
+			success = false
+		}
+	}
+
+	return success
+}
@@ -0,0 +1,31 @@
+package commander
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPadWaitCapacity(t *testing.T) {
+	tests := []struct {
+		name        string
+		profile     MissionProfile
+		freeMB      uint32
+		bytesPerSec float64
+		padWait     time.Duration
+	}{
+		{"no flight reserved", MissionProfile{}, 100, 1000, 100_000 * time.Second},
+		{"flight at the pad rate", MissionProfile{Flight: 10 * time.Minute, FlightRateMultiplier: 1}, 100, 1000, 99_400 * time.Second},
+		{"unset multiplier is the pad rate", MissionProfile{Flight: 10 * time.Minute}, 100, 1000, 99_400 * time.Second},
+		{"flight logs four times faster", MissionProfile{Flight: 10 * time.Minute, FlightRateMultiplier: 4}, 100, 1000, 97_600 * time.Second},
+		{"flight alone does not fit", MissionProfile{Flight: 10 * time.Minute, FlightRateMultiplier: 1}, 1, 10_000, -500 * time.Second},
+		{"full card", MissionProfile{Flight: 10 * time.Minute, FlightRateMultiplier: 1}, 0, 1000, -10 * time.Minute},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			padWait := test.profile.padWaitCapacity(test.freeMB, test.bytesPerSec)
+			if diff := padWait - test.padWait; diff > time.Millisecond || diff < -time.Millisecond {
+				t.Fatalf("pad wait %s, want %s", padWait, test.padWait)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package commander
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// the board stamps its reply somewhere in the middle of the round trip, so compare
+// it against the host time half way between sending and receiving
+func clockOffset(sentAt time.Time, roundTrip time.Duration, boardMicros int64) time.Duration {
+	hostMicros := sentAt.Add(roundTrip / 2).UnixMicro()
+	return time.Duration(boardMicros-hostMicros) * time.Microsecond
+}
+
+// the board an opcode belongs to, empty for the radio
+func boardKey(opcode byte) string {
+	board, revision := OpcodeBoard(opcode)
+	if board == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s v%d", board, revision)
+}
+
+type clockSample struct {
+	hostMicros int64
+	offset     time.Duration
+}
+
+// least squares slope of the offset against host time, scaled to parts per million
+func driftPPM(samples []clockSample) float64 {
+	n := float64(len(samples))
+	var sumX, sumY, sumXY, sumXX float64
+	for _, s := range samples {
+		x := float64(s.hostMicros - samples[0].hostMicros)
+		y := float64(s.offset.Microseconds())
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+
+	denom := n*sumXX - sumX*sumX
+	if denom == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denom * 1e6
+}
+
+// jump the clocks once, then follow the live sensor timestamps for the given duration and
+// estimate how fast each board drifts from the host, there is no opcode that reads a clock back
+func MeasureClockDrift(conn SerialReaderWriter, log io.Writer, sources []TimingSource, maxOffset time.Duration, duration time.Duration, interval time.Duration, maxPPM float64) bool {
+	// the SD timestamp is the last record written, which stops moving in inspect mode
+	live := []TimingSource{}
+	liveBoards := map[string]bool{}
+	for _, source := range sources {
+		if source.Kind != TIMESTAMP_SD_UPDATE {
+			live = append(live, source)
+			liveBoards[boardKey(source.Command)] = true
+		}
+	}
+
+	// boards with nothing but an SD timestamp, i.e. the radio and analog boards, cannot be followed
+	unmeasured := []string{}
+	for _, source := range sources {
+		if source.Kind == TIMESTAMP_SD_UPDATE && !liveBoards[boardKey(source.Command)] {
+			unmeasured = append(unmeasured, source.Name)
+		}
+	}
+	if len(unmeasured) > 0 {
+		fmt.Fprintf(log, "[Clock Drift]: Not measured, no live timestamp: %s\n", strings.Join(unmeasured, ", "))
+	}
+	if len(live) == 0 {
+		fmt.Fprintf(log, "[Clock Drift]: No board with live timestamps to follow\n")
+		return false
+	}
+
+	fmt.Fprintf(log, "[Clock Drift]: Jumping clocks to line the boards up with the host\n")
+	if !JumpClocks(conn, log, maxOffset) {
+		fmt.Fprintf(log, "[Clock Drift]: Failed to jump clocks\n")
+		return false
+	}
+
+	fmt.Fprintf(log, "[Clock Drift]: Sampling %d sources every %s for %s\n", len(live), interval, duration)
+
+	samples := make([][]clockSample, len(live))
+	deadline := time.Now().Add(duration)
+	for {
+		for i, source := range live {
+			sample, ok := sampleTimestamp(conn, log, "Clock Drift", source)
+			if !ok {
+				continue
+			}
+			offset := sample.boardTime.Sub(sample.hostTime)
+			samples[i] = append(samples[i], clockSample{hostMicros: sample.hostTime.UnixMicro(), offset: offset})
+			fmt.Fprintf(log, "[Clock Drift]: %s offset %s\n", source.Name, offset)
+		}
+
+		if time.Now().Add(interval).After(deadline) {
+			break
+		}
+		time.Sleep(interval)
+	}
+
+	success := true
+	for i, source := range live {
+		if len(samples[i]) < 2 {
+			fmt.Fprintf(log, "[Clock Drift]: %s: not enough samples to estimate drift\n", source.Name)
+			success = false
+			continue
+		}
+
+		ppm := driftPPM(samples[i])
+		fmt.Fprintf(log, "[Clock Drift]: %s drift %.1f ppm over %d samples\n", source.Name, ppm, len(samples[i]))
+		if ppm > maxPPM || ppm < -maxPPM {
+			fmt.Fprintf(log, "[Clock Drift]: %s drifts more than %.1f ppm\n", source.Name, maxPPM)
+			success = false
+		}
+	}
+
+	return success
+}
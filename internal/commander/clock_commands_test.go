@@ -0,0 +1,48 @@
+package commander
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDriftPPM(t *testing.T) {
+	// offsets every 10 seconds, a board that gains 1ms over 10s drifts 100 ppm
+	line := func(startOffset time.Duration, perStep time.Duration, steps int) []clockSample {
+		samples := []clockSample{}
+		for i := range steps {
+			samples = append(samples, clockSample{
+				hostMicros: 1_700_000_000_000_000 + int64(i)*10_000_000,
+				offset:     startOffset + time.Duration(i)*perStep,
+			})
+		}
+		return samples
+	}
+
+	tests := []struct {
+		name    string
+		samples []clockSample
+		ppm     float64
+	}{
+		{"steady clock", line(3*time.Millisecond, 0, 10), 0},
+		{"fast clock", line(0, time.Millisecond, 10), 100},
+		{"slow clock", line(0, -500*time.Microsecond, 10), -50},
+		{"offset does not matter", line(-2*time.Second, time.Millisecond, 10), 100},
+		{"two samples", line(0, 100*time.Microsecond, 2), 10},
+		{"single sample", line(0, time.Millisecond, 1), 0},
+		{"noisy around a slope", []clockSample{
+			{hostMicros: 0, offset: 0},
+			{hostMicros: 10_000_000, offset: 1100 * time.Microsecond},
+			{hostMicros: 20_000_000, offset: 1900 * time.Microsecond},
+			{hostMicros: 30_000_000, offset: 3000 * time.Microsecond},
+		}, 98},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ppm := driftPPM(test.samples)
+			if math.Abs(ppm-test.ppm) > 0.01 {
+				t.Fatalf("drift %.3f ppm, want %.3f", ppm, test.ppm)
+			}
+		})
+	}
+}
@@ -6,34 +6,36 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"math"
 	"time"
 )
 
 const COMMAND_SEQUENCE_SIZE = 4
 const COMMAND_BYTE_IDX = 2
 
-const SD_CARD_TEST_TIMEOUT = 5 * time.Second
-
 type SerialReaderWriter interface {
 	WriteSingleMessage(message []byte, size int)
 	ReadSingleOrTimeout() ([]byte, error)
 }
 
+// a connection that can give up on a reply sooner than its read timeout
+type TimedReader interface {
+	ReadSingleWithin(timeout time.Duration) ([]byte, error)
+}
+
+// read with a shorter wait when conn supports it, otherwise with its own read timeout
+func readWithin(conn SerialReaderWriter, timeout time.Duration) ([]byte, error) {
+	if timed, ok := conn.(TimedReader); ok {
+		return timed.ReadSingleWithin(timeout)
+	}
+	return conn.ReadSingleOrTimeout()
+}
+
 func getDispatchCommand(cmd byte) [COMMAND_SEQUENCE_SIZE]byte {
 	// for consistency with terminal, use carraige return when sending back a command
 	return [COMMAND_SEQUENCE_SIZE]byte{cmd, '+', '+', '+'}
 }
 
-// to verify that the SD card is working
-// 1. Check the current file size and the timestamp
-// 2. Return the system back to normal mode and wait for 10s
-// 3. Send the system back into inspect mode
-// 4. Check the new file size and timestamp, it should be bigger than the previous one
-type sdUpdate struct {
-	LastTimestamp int64
-	FileSize      uint32
-}
-
 func getSDUpdate(conn SerialReaderWriter, log io.Writer, command byte) *sdUpdate {
 	sdUpdateMessage := getDispatchCommand(command)
 	conn.WriteSingleMessage(sdUpdateMessage[:], COMMAND_SEQUENCE_SIZE)
@@ -64,12 +66,50 @@ func getSDUpdate(conn SerialReaderWriter, log io.Writer, command byte) *sdUpdate
 	return &updateData
 }
 
-type jumpClockUplinkPaylod struct {
-	CommandCode            uint8
-	CurrentTimeStampMicros int64
+// SD logger state of a board, without switching modes so it can be polled while logging
+type SDReading struct {
+	FileSize      uint32
+	LastTimestamp int64
+}
+
+// enters inspect mode first like every other SD check, the monitor puts the boards back to logging between cycles
+func SampleSD(conn SerialReaderWriter, log io.Writer, command byte) *SDReading {
+	if !EnterInspectCommand(conn, log) {
+		fmt.Fprintf(log, "[SD Update]: Failed to enter inspect mode\n")
+		return nil
+	}
+
+	update := getSDUpdate(conn, log, command)
+	if update == nil {
+		return nil
+	}
+	return &SDReading{FileSize: update.FileSize, LastTimestamp: update.LastTimestamp}
+}
+
+// send a single request and decode the reply into out, returning the host time
+// half way through the round trip so callers can line it up with board timestamps
+func requestReading(conn SerialReaderWriter, log io.Writer, tag string, command byte, out any) (time.Time, bool) {
+	cmd := getDispatchCommand(command)
+	sentAt := time.Now()
+	conn.WriteSingleMessage(cmd[:], COMMAND_SEQUENCE_SIZE)
+
+	res, err := conn.ReadSingleOrTimeout()
+	if err != nil {
+		fmt.Fprintf(log, "[%s]: Read timed out\n", tag)
+		return time.Time{}, false
+	}
+	midpoint := sentAt.Add(time.Since(sentAt) / 2)
+
+	streamReader := bytes.NewReader(res[:])
+	if err := binary.Read(streamReader, binary.LittleEndian, out); err != nil {
+		fmt.Fprintf(log, "[%s]: Could not decode board response, %s\n", tag, err)
+		return time.Time{}, false
+	}
+
+	return midpoint, true
 }
 
-func JumpClocks(conn SerialReaderWriter, log io.Writer) bool {
+func JumpClocks(conn SerialReaderWriter, log io.Writer, maxOffset time.Duration) bool {
 	// enter inspect mode first
 	fmt.Fprintf(log, "[Jump Clock]: Entering inspect mode\n")
 	if !EnterInspectCommand(conn, log) {
@@ -83,7 +123,8 @@ func JumpClocks(conn SerialReaderWriter, log io.Writer) bool {
 	// add 3 for the command sequence at the end
 	messageBytes := make([]byte, 9+3)
 	// Pack the bytes manually (using Little Endian here)
-	clkMicro := time.Now().UnixMicro()
+	sentAt := time.Now()
+	clkMicro := sentAt.UnixMicro()
 	messageBytes[0] = byte(globals.CMD_JUMP_CLK)
 	binary.LittleEndian.PutUint64(messageBytes[1:9], uint64(clkMicro))
 	copy(messageBytes[9:12], []byte("+++"))
@@ -96,6 +137,7 @@ func JumpClocks(conn SerialReaderWriter, log io.Writer) bool {
 		fmt.Fprintf(log, "[Jump Clock]: Read timed out")
 		return false
 	}
+	roundTrip := time.Since(sentAt)
 
 	fmt.Fprintf(log, "[Jump Clock]: Receieved response from boards of len %d\n", len(res))
 	streamReader := bytes.NewReader(res[:])
@@ -105,14 +147,42 @@ func JumpClocks(conn SerialReaderWriter, log io.Writer) bool {
 		return false
 	}
 
+	offset := clockOffset(sentAt, roundTrip, boardClock)
 	fmt.Fprintf(
 		log,
-		"[Jump Clock]: Radio board timestamp %d\n", boardClock,
+		"[Jump Clock]: Radio board timestamp %d\n[Jump Clock]: Round trip: %s, offset from host: %s (limit %s)\n",
+		boardClock, roundTrip, offset, maxOffset,
 	)
+
+	if offset.Abs() > maxOffset {
+		fmt.Fprintf(log, "[Jump Clock]: Board clock is off by more than %s\n", maxOffset)
+		return false
+	}
+
+	withSession(conn, func(s *sessionState) {
+		s.lastJump = sentAt
+		s.lastJumpOffset = offset
+	})
+
 	return true
 }
 
-func InspectSDCards(conn SerialReaderWriter, log io.Writer, boardType string, command byte, canBeZero bool) bool {
+// what a healthy SD card logger should look like, per board
+type SDExpectation struct {
+	// how long the board is left logging in normal mode
+	Window time.Duration
+
+	// slowest logging rate accepted, 0 disables the check
+	MinBytesPerSec float64
+
+	// how far the timestamp advance may stray from the wall clock, as a fraction
+	TimestampTolerance float64
+
+	// pad wait plus flight that the remaining space has to hold
+	Mission MissionProfile
+}
+
+func InspectSDCards(conn SerialReaderWriter, log io.Writer, boardType string, command byte, canBeZero bool, expected SDExpectation) bool {
 	fmt.Fprintf(log, "[Check %s SD]: Entering inspect mode\n", boardType)
 	if !EnterInspectCommand(conn, log) {
 		fmt.Fprintf(log, "[Check %s SD]: Failed to enter inspect mode\n", boardType)
@@ -131,13 +201,15 @@ func InspectSDCards(conn SerialReaderWriter, log io.Writer, boardType string, co
 		fmt.Fprintf(log, "[Check %s SD]: Failed to enter normal mode\n", boardType)
 		return false
 	}
+	loggingStart := time.Now()
 
-	time.Sleep(SD_CARD_TEST_TIMEOUT)
+	time.Sleep(expected.Window)
 	fmt.Fprintf(log, "[Check %s SD]: Entering inspect mode\n", boardType)
 	if !EnterInspectCommand(conn, log) {
 		fmt.Fprintf(log, "[Check %s SD]: Failed to enter inspect mode\n", boardType)
 		return false
 	}
+	loggingTime := time.Since(loggingStart)
 
 	time.Sleep(1 * time.Second)
 	fmt.Fprintf(log, "[Check %s SD]: Dispatching sd card checker again\n", boardType)
@@ -147,7 +219,67 @@ func InspectSDCards(conn SerialReaderWriter, log io.Writer, boardType string, co
 		return false
 	}
 
-	return canBeZero || (firstUpdate.FileSize < secondUpdate.FileSize && firstUpdate.LastTimestamp < secondUpdate.LastTimestamp)
+	if firstUpdate.FileSize >= secondUpdate.FileSize || firstUpdate.LastTimestamp >= secondUpdate.LastTimestamp {
+		if canBeZero {
+			// the capacity plan still needs the rate, a board that is not logging is recorded at 0
+			bytesPerSec := 0.0
+			if secondUpdate.FileSize > firstUpdate.FileSize {
+				bytesPerSec = float64(secondUpdate.FileSize-firstUpdate.FileSize) / loggingTime.Seconds()
+			}
+			withSession(conn, func(s *sessionState) {
+				s.sdBytesPerSec[boardType] = bytesPerSec
+			})
+			fmt.Fprintf(log, "[Check %s SD]: File size or timestamp did not increase, which is allowed for this board (%.0f B/s)\n", boardType, bytesPerSec)
+			return true
+		}
+		fmt.Fprintf(log, "[Check %s SD]: File size or timestamp did not increase\n", boardType)
+		return false
+	}
+
+	bytesPerSec := float64(secondUpdate.FileSize-firstUpdate.FileSize) / loggingTime.Seconds()
+	timestampAdvance := time.Duration(secondUpdate.LastTimestamp-firstUpdate.LastTimestamp) * time.Microsecond
+	fmt.Fprintf(
+		log,
+		"[Check %s SD]: Logging rate %.0f B/s, timestamp advanced %s over %s of wall clock\n",
+		boardType, bytesPerSec, timestampAdvance, loggingTime,
+	)
+
+	withSession(conn, func(s *sessionState) {
+		s.sdBytesPerSec[boardType] = bytesPerSec
+	})
+
+	success := true
+	if expected.MinBytesPerSec > 0 && bytesPerSec < expected.MinBytesPerSec {
+		fmt.Fprintf(log, "[Check %s SD]: Logging rate is below the expected %.0f B/s\n", boardType, expected.MinBytesPerSec)
+		success = false
+	}
+
+	ratio := timestampAdvance.Seconds() / loggingTime.Seconds()
+	if math.Abs(ratio-1) > expected.TimestampTolerance {
+		fmt.Fprintf(
+			log, "[Check %s SD]: Timestamp advanced %.0f%% of the wall clock, expected within %.0f%%\n",
+			boardType, ratio*100, expected.TimestampTolerance*100,
+		)
+		success = false
+	}
+
+	var freeMB uint32
+	var knownFree bool
+	withSession(conn, func(s *sessionState) {
+		freeMB, knownFree = s.sdFreeMB[boardType]
+	})
+	if !knownFree {
+		fmt.Fprintf(log, "[Check %s SD]: Free space unknown, clear the SD card to check it against the mission duration\n", boardType)
+		return success
+	}
+
+	padWait := expected.Mission.padWaitCapacity(freeMB, bytesPerSec)
+	fmt.Fprintf(log, "[Check %s SD]: %d MB free holds %s of pad wait plus the flight\n", boardType, freeMB, padWait.Round(time.Minute))
+	if padWait < expected.Mission.PadWait {
+		fmt.Fprintf(log, "[Check %s SD]: WARNING remaining space cannot hold %s\n", boardType, expected.Mission)
+	}
+
+	return success
 }
 
 func ClearSDCard(conn SerialReaderWriter, log io.Writer, boardType string, command byte) bool {
@@ -177,5 +309,9 @@ func ClearSDCard(conn SerialReaderWriter, log io.Writer, boardType string, comma
 
 	fmt.Fprintf(log, "[Clear %s SD]: Clear command acknowledged. Free space is now: %d MB\n", boardType, freeSpace)
 
+	withSession(conn, func(s *sessionState) {
+		s.sdFreeMB[boardType] = freeSpace
+	})
+
 	return true
 }
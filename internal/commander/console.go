@@ -0,0 +1,46 @@
+package commander
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// every frame ends with the same terminator getDispatchCommand appends
+var FRAME_TERMINATOR = []byte("+++")
+
+// turn what the operator typed into a frame, e.g. "0xD5", "d5 01 02" or "0xD5,0x01"
+// the terminator is appended unless the input already ends with it
+func ParseFrame(input string) ([]byte, error) {
+	tokens := strings.FieldsFunc(input, func(r rune) bool {
+		return r == ' ' || r == ',' || r == '\t'
+	})
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("nothing to send")
+	}
+
+	frame := []byte{}
+	for _, token := range tokens {
+		digits := strings.TrimPrefix(strings.TrimPrefix(token, "0x"), "0X")
+		if len(digits)%2 == 1 {
+			digits = "0" + digits
+		}
+		decoded, err := hex.DecodeString(digits)
+		if err != nil || len(decoded) == 0 {
+			return nil, fmt.Errorf("%q is not hex", token)
+		}
+		frame = append(frame, decoded...)
+	}
+
+	if !bytes.HasSuffix(frame, FRAME_TERMINATOR) {
+		frame = append(frame, FRAME_TERMINATOR...)
+	}
+	return frame, nil
+}
+
+// send a frame as is and return the raw reply, for commands that are not in the menus yet
+func SendRaw(conn SerialReaderWriter, frame []byte) ([]byte, error) {
+	conn.WriteSingleMessage(frame, len(frame))
+	return conn.ReadSingleOrTimeout()
+}
@@ -0,0 +1,175 @@
+package commander
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+)
+
+type DecodedField struct {
+	Name   string
+	Offset int
+	Raw    []byte
+	Value  string
+}
+
+type Decoded struct {
+	// Go struct the bytes were decoded into
+	Layout string
+	Fields []DecodedField
+
+	// bytes left over after the struct, usually means the firmware layout changed
+	Trailing []byte
+}
+
+// decode raw bytes into layout and list every field with the bytes it came from
+func decodeInto(layout any, raw []byte) (*Decoded, error) {
+	size := binary.Size(layout)
+	if len(raw) < size {
+		return nil, fmt.Errorf("%d bytes is too short for %s, which needs %d", len(raw), reflect.TypeOf(layout).Elem().Name(), size)
+	}
+	if err := binary.Read(bytes.NewReader(raw), binary.LittleEndian, layout); err != nil {
+		return nil, err
+	}
+
+	decoded := &Decoded{
+		Layout:   reflect.TypeOf(layout).Elem().Name(),
+		Trailing: raw[size:],
+	}
+
+	v := reflect.ValueOf(layout).Elem()
+	offset := 0
+	var walk func(name string, value reflect.Value)
+	walk = func(name string, value reflect.Value) {
+		switch value.Kind() {
+		case reflect.Array:
+			for i := range value.Len() {
+				walk(fmt.Sprintf("%s[%d]", name, i), value.Index(i))
+			}
+		case reflect.Struct:
+			for i := range value.NumField() {
+				walk(name+"."+value.Type().Field(i).Name, value.Field(i))
+			}
+		default:
+			width := binary.Size(value.Interface())
+			decoded.Fields = append(decoded.Fields, DecodedField{
+				Name:   name,
+				Offset: offset,
+				Raw:    raw[offset : offset+width],
+				Value:  fmt.Sprintf("%v", value.Interface()),
+			})
+			offset += width
+		}
+	}
+	for i := range v.NumField() {
+		walk(v.Type().Field(i).Name, v.Field(i))
+	}
+
+	return decoded, nil
+}
+
+// decode a reply to opcode the way the command that sent it would, field by field
+func DecodeResponse(opcode byte, raw []byte) (*Decoded, error) {
+	layout, ok := responseLayout(opcode)
+	if !ok {
+		return nil, fmt.Errorf("no known reply layout for opcode 0x%02X", opcode)
+	}
+
+	// an error code in place of the struct, only recognised when the reply is too short to be the struct
+	if len(raw) > 0 && len(raw) < binary.Size(layout) {
+		if name, ok := ErrorCodeName(raw[0]); ok {
+			decoded, err := decodeInto(errorCodeLayout(raw[0]), raw)
+			if err != nil {
+				decoded, err = decodeInto(&ackReply{}, raw)
+			}
+			if err != nil {
+				return nil, err
+			}
+			decoded.Layout = name
+			return decoded, nil
+		}
+	}
+
+	return decodeInto(layout, raw)
+}
+
+// decode the payload of a request frame, nil when the opcode is sent without one
+func DecodeRequest(frame []byte) (*Decoded, error) {
+	if len(frame) == 0 {
+		return nil, fmt.Errorf("empty frame")
+	}
+	layout, ok := requestLayout(frame[0])
+	if !ok {
+		return nil, nil
+	}
+	return decodeInto(layout, bytes.TrimSuffix(frame, FRAME_TERMINATOR))
+}
+
+// every known reply struct that raw fits exactly, for opcodes that are not in the menus yet
+func DecodeCandidates(raw []byte) []*Decoded {
+	candidates := []*Decoded{}
+	for _, layout := range knownLayouts() {
+		if binary.Size(layout) != len(raw) {
+			continue
+		}
+		if decoded, err := decodeInto(layout, raw); err == nil {
+			candidates = append(candidates, decoded)
+		}
+	}
+	return candidates
+}
+
+// field names of the reply to opcode the way DecodeResponse lists them, for checking rule files
+func ResponseFields(opcode byte) ([]string, bool) {
+	layout, ok := responseLayout(opcode)
+	if !ok {
+		return nil, false
+	}
+	decoded, err := decodeInto(layout, make([]byte, binary.Size(layout)))
+	if err != nil {
+		return nil, false
+	}
+
+	names := []string{}
+	for _, field := range decoded.Fields {
+		names = append(names, field.Name)
+	}
+	return names, true
+}
+
+// the opcode of a command or host action by its name in the protocol schema
+func OpcodeByName(name string) (byte, bool) {
+	for opcode := range 256 {
+		if opcodeName, ok := OpcodeName(byte(opcode)); ok && opcodeName == name {
+			return byte(opcode), true
+		}
+	}
+	return 0, false
+}
+
+// whether frame can be the reply to opcode, the firmware does not tag replies so acks
+// are matched on the echoed opcode and everything else on being long enough for its struct,
+// trailing bytes are decoded leniently the same as before replies were matched
+func MatchesResponse(opcode byte, frame []byte) bool {
+	layout, ok := responseLayout(opcode)
+	if !ok {
+		// raw console frames and unknown opcodes take whatever comes back
+		return true
+	}
+	if len(frame) == 0 {
+		return false
+	}
+
+	// an error code in place of the struct, bare or with the struct it carries
+	if _, ok := ErrorCodeName(frame[0]); ok {
+		if len(frame) == 1 || len(frame) == binary.Size(errorCodeLayout(frame[0])) {
+			return true
+		}
+	}
+
+	if _, isAck := layout.(*ackReply); isAck {
+		return len(frame) == 1 && frame[0] == opcode
+	}
+	return len(frame) >= binary.Size(layout)
+}
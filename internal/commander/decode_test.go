@@ -0,0 +1,38 @@
+package commander
+
+import (
+	"UCLA-Rocket-Project/ILAYE/internal/globals"
+	"encoding/binary"
+	"testing"
+)
+
+func TestMatchesResponse(t *testing.T) {
+	ptSize := binary.Size(&ptUpdate{})
+	pt := func(size int) []byte { return make([]byte, size) }
+
+	tests := []struct {
+		name   string
+		opcode byte
+		frame  []byte
+		match  bool
+	}{
+		{"ack echoes the opcode", globals.CMD_ENTER_INSPECT, []byte{globals.CMD_ENTER_INSPECT}, true},
+		{"ack of another command", globals.CMD_ENTER_INSPECT, []byte{globals.CMD_ENTER_NORMAL}, false},
+		{"ack with trailing bytes", globals.CMD_ENTER_INSPECT, []byte{globals.CMD_ENTER_INSPECT, 0}, false},
+		{"empty frame", globals.CMD_GET_ANALOG_V1_PT_READING, []byte{}, false},
+		{"struct of the exact size", globals.CMD_GET_ANALOG_V1_PT_READING, pt(ptSize), true},
+		{"struct with trailing bytes", globals.CMD_GET_ANALOG_V1_PT_READING, pt(ptSize + 4), true},
+		{"struct cut short", globals.CMD_GET_ANALOG_V1_PT_READING, pt(ptSize - 1), false},
+		{"bare error code for a struct", globals.CMD_GET_ANALOG_V1_PT_READING, []byte{globals.CMD_TIMEOUT}, true},
+		{"bare error code for an ack", globals.CMD_ENTER_INSPECT, []byte{globals.RADIO_COMMAND_NOT_RECOGNIZED}, true},
+		{"error code with its struct", globals.CMD_ENTER_NORMAL, append([]byte{globals.CAN_RESPONSE_WRONG}, make([]byte, binary.Size(&ModeTransitionErrorResponse{})-1)...), true},
+		{"host action takes anything", globals.HOST_TIMESTAMP_CHECK, []byte{1, 2, 3}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := MatchesResponse(test.opcode, test.frame); got != test.match {
+				t.Fatalf("MatchesResponse(0x%02X, % x) = %v, want %v", test.opcode, test.frame, got, test.match)
+			}
+		})
+	}
+}
@@ -2,179 +2,491 @@ package commander
 
 import (
 	"UCLA-Rocket-Project/ILAYE/internal/globals"
-	"bytes"
-	"encoding/binary"
 	"fmt"
 	"io"
+	"math"
+	"strings"
+	"time"
 )
 
 type ShockAccelNum byte
 
-type shockData struct {
-	AccX float32
-	AccY float32
-	AccZ float32
+type ShockReading struct {
+	AccX float64
+	AccY float64
+	AccZ float64
 }
 
-func CheckDigitalShockCmd(conn SerialReaderWriter, log io.Writer, digitalBoardVersion string, command byte) bool {
-	var shockNum int
-
+func shockNumber(command byte) int {
 	switch command {
-	case globals.CMD_GET_DIGITAL_V1_SHOCK_1_READING:
-		shockNum = 1
-	case globals.CMD_GET_DIGITAL_V2_SHOCK_1_READING:
-		shockNum = 1
 	case globals.CMD_GET_DIGITAL_V2_SHOCK_2_READING:
-		shockNum = 2
+		return 2
+	default:
+		return 1
 	}
+}
 
-	fmt.Fprintf(log, "[Check Digital %s Shock %d]: Entering inspect mode\n", digitalBoardVersion, shockNum)
+func SampleShock(conn SerialReaderWriter, log io.Writer, digitalBoardVersion string, command byte) *ShockReading {
+	shockNum := shockNumber(command)
+	tag := fmt.Sprintf("Check Digital %s Shock %d", digitalBoardVersion, shockNum)
+
+	fmt.Fprintf(log, "[%s]: Entering inspect mode\n", tag)
 	if !EnterInspectCommand(conn, log) {
-		fmt.Fprintf(log, "[Check Digital %s Shock %d]: Failed to enter inspect mode\n", digitalBoardVersion, shockNum)
-		return false
+		fmt.Fprintf(log, "[%s]: Failed to enter inspect mode\n", tag)
+		return nil
 	}
 
-	shockUpdateMessage := getDispatchCommand(command)
-	conn.WriteSingleMessage(shockUpdateMessage[:], COMMAND_SEQUENCE_SIZE)
-	fmt.Fprintf(log, "[Check Digital %s Shock %d]: Sent command requesting Shock %d update\n", digitalBoardVersion, shockNum, shockNum)
+	fmt.Fprintf(log, "[%s]: Sent command requesting Shock %d update\n", tag, shockNum)
+	var shockData shockData
+	if _, ok := requestReading(conn, log, tag, command, &shockData); !ok {
+		return nil
+	}
 
-	res, err := conn.ReadSingleOrTimeout()
-	if err != nil {
-		fmt.Fprintf(log, "[Check Digital %s Shock %d]: Read timed out", digitalBoardVersion, shockNum)
-		return false
+	return &ShockReading{
+		AccX: float64(shockData.AccX),
+		AccY: float64(shockData.AccY),
+		AccZ: float64(shockData.AccZ),
 	}
-	fmt.Fprintf(log, "[Check Digital %s Shock %d]: Receieved response from boards of len %d\n", digitalBoardVersion, shockNum, len(res))
-	streamReader := bytes.NewReader(res[:])
-	var shockData shockData
-	if err := binary.Read(streamReader, binary.LittleEndian, &shockData); err != nil {
-		fmt.Fprintf(log, "[Check Digital %s Shock %d]: Error decoding shock response\n", digitalBoardVersion, shockNum)
+}
+
+func CheckDigitalShockCmd(conn SerialReaderWriter, log io.Writer, digitalBoardVersion string, command byte) bool {
+	reading := SampleShock(conn, log, digitalBoardVersion, command)
+	if reading == nil {
 		return false
 	}
 
 	fmt.Fprintf(
 		log,
 		"[Check Digital %s Shock %d]: \nShock data: %f, %f, %f\n",
-		digitalBoardVersion, shockNum, shockData.AccX, shockData.AccY, shockData.AccZ,
+		digitalBoardVersion, shockNumber(command), reading.AccX, reading.AccY, reading.AccZ,
 	)
 	return true
 }
 
-type IMUData struct {
-	AccX float32
-	AccY float32
-	AccZ float32
+func (d shockData) acc() vec3 {
+	return vec3{float64(d.AccX), float64(d.AccY), float64(d.AccZ)}
+}
 
-	GyrX float32
-	GyrY float32
-	GyrZ float32
+// how a shock accelerometer sits relative to the IMU
+type SensorMounting struct {
+	// multiplies the raw reading into the IMU's units, 0 is treated as 1
+	Scale float64
 
-	Timestamp uint32
+	// rotates the sensor frame into the IMU frame, all zero is treated as identity
+	Rotation [3][3]float64
 }
 
-func CheckDigitalIMUCommand(conn SerialReaderWriter, log io.Writer, digitalBoardVersion string, command byte) bool {
-	fmt.Fprintf(log, "[Check Digital %s IMU]: Entering inspect mode\n", digitalBoardVersion)
+func (s SensorMounting) toIMUFrame(acc vec3) vec3 {
+	if !mat3(s.Rotation).isZero() {
+		acc = mat3(s.Rotation).mul(acc)
+	}
+	if s.Scale != 0 {
+		acc = acc.scale(s.Scale)
+	}
+	return acc
+}
+
+type ShockCrossCheck struct {
+	Shock1 SensorMounting
+	Shock2 SensorMounting
+
+	// smallest magnitude a live sensor reads at rest, in the IMU's units
+	DeadThreshold float64
+
+	// largest difference between any two sensors after rotation, in the IMU's units
+	Tolerance float64
+}
+
+// sample both shock accelerometers and the IMU back to back and check that they agree once
+// mounted in the same frame, a dead or misaligned sensor decodes fine but disagrees here
+func CrossCheckShockAccels(conn SerialReaderWriter, log io.Writer, digitalBoardVersion string, shock1Cmd byte, shock2Cmd byte, imuCmd byte, check ShockCrossCheck) bool {
+	tag := fmt.Sprintf("Shock Cross Check %s", digitalBoardVersion)
+
+	fmt.Fprintf(log, "[%s]: Entering inspect mode\n", tag)
 	if !EnterInspectCommand(conn, log) {
-		fmt.Fprintf(log, "[Check Digital %s IMU]: Failed to enter inspect mode\n", digitalBoardVersion)
+		fmt.Fprintf(log, "[%s]: Failed to enter inspect mode\n", tag)
 		return false
 	}
 
-	sdUpdateMessage := getDispatchCommand(command)
-	conn.WriteSingleMessage(sdUpdateMessage[:], COMMAND_SEQUENCE_SIZE)
-	fmt.Fprintf(log, "[Chceck Digital %s IMU]: Sent command requesting IMU update\n", digitalBoardVersion)
-
-	res, err := conn.ReadSingleOrTimeout()
-	if err != nil {
-		fmt.Fprintf(log, "[Check Digital %s IMU]: Read timed out", digitalBoardVersion)
+	var shock1, shock2 shockData
+	var imu IMUData
+	if _, ok := requestReading(conn, log, tag, shock1Cmd, &shock1); !ok {
+		return false
+	}
+	if _, ok := requestReading(conn, log, tag, shock2Cmd, &shock2); !ok {
+		return false
+	}
+	if _, ok := requestReading(conn, log, tag, imuCmd, &imu); !ok {
 		return false
 	}
-	fmt.Fprintf(log, "[Check Digital %s IMU]: Receieved response from boards\n", digitalBoardVersion)
-	streamReader := bytes.NewReader(res[:])
+
+	names := []string{"Shock 1", "Shock 2", "IMU"}
+	readings := []vec3{
+		check.Shock1.toIMUFrame(shock1.acc()),
+		check.Shock2.toIMUFrame(shock2.acc()),
+		imu.acc(),
+	}
+	for i, acc := range readings {
+		fmt.Fprintf(log, "[%s]: %s in IMU frame: %.3f, %.3f, %.3f\n", tag, names[i], acc[0], acc[1], acc[2])
+	}
+
+	success := true
+	for i, acc := range readings {
+		if acc.norm() < check.DeadThreshold {
+			fmt.Fprintf(log, "[%s]: %s reads almost nothing, sensor may be dead\n", tag, names[i])
+			success = false
+		}
+	}
+
+	// count how many pairs each sensor is part of that disagree, the odd one out shows up in both
+	disagreements := make([]int, len(readings))
+	for i := range readings {
+		for j := i + 1; j < len(readings); j++ {
+			diff := readings[i].sub(readings[j]).norm()
+			fmt.Fprintf(
+				log, "[%s]: %s vs %s: difference %.3f, angle %.1f°\n",
+				tag, names[i], names[j], diff, readings[i].angleTo(readings[j]),
+			)
+			if diff > check.Tolerance {
+				disagreements[i]++
+				disagreements[j]++
+				success = false
+			}
+		}
+	}
+
+	for i, count := range disagreements {
+		if count == len(readings)-1 {
+			fmt.Fprintf(log, "[%s]: %s disagrees with the other sensors, check it is alive and mounted as configured\n", tag, names[i])
+		}
+	}
+
+	return success
+}
+
+func (d IMUData) acc() vec3 {
+	return vec3{float64(d.AccX), float64(d.AccY), float64(d.AccZ)}
+}
+
+func (d IMUData) gyr() vec3 {
+	return vec3{float64(d.GyrX), float64(d.GyrY), float64(d.GyrZ)}
+}
+
+func SampleIMU(conn SerialReaderWriter, log io.Writer, digitalBoardVersion string, command byte) *IMUData {
+	tag := fmt.Sprintf("Check Digital %s IMU", digitalBoardVersion)
+
+	fmt.Fprintf(log, "[%s]: Entering inspect mode\n", tag)
+	if !EnterInspectCommand(conn, log) {
+		fmt.Fprintf(log, "[%s]: Failed to enter inspect mode\n", tag)
+		return nil
+	}
+
 	var updateData IMUData
-	if err := binary.Read(streamReader, binary.LittleEndian, &updateData); err != nil {
+	if _, ok := requestReading(conn, log, tag, command, &updateData); !ok {
+		return nil
+	}
+	return &updateData
+}
+
+// what a healthy IMU sitting on the rail should report
+type IMUExpectation struct {
+	// accelerometer magnitude at rest and how far it may be off, in the IMU's units
+	Gravity          float64
+	GravityTolerance float64
+
+	// largest mean gyro rate accepted while the vehicle is still
+	MaxGyroBias float64
+
+	// readings averaged for the bias and orientation estimate
+	Samples int
+
+	// direction in the IMU frame that points up on the rail, all zero skips the check
+	UpAxis       [3]float64
+	ToleranceDeg float64
+}
+
+func CheckDigitalIMUCommand(conn SerialReaderWriter, log io.Writer, digitalBoardVersion string, command byte, expected IMUExpectation) bool {
+	tag := fmt.Sprintf("Check Digital %s IMU", digitalBoardVersion)
+
+	fmt.Fprintf(log, "[%s]: Entering inspect mode\n", tag)
+	if !EnterInspectCommand(conn, log) {
+		fmt.Fprintf(log, "[%s]: Failed to enter inspect mode\n", tag)
 		return false
 	}
+
+	samples := max(expected.Samples, 1)
+	fmt.Fprintf(log, "[%s]: Requesting %d IMU updates\n", tag, samples)
+
+	var meanAcc, meanGyr vec3
+	for i := range samples {
+		var updateData IMUData
+		if _, ok := requestReading(conn, log, tag, command, &updateData); !ok {
+			return false
+		}
+
+		if i == 0 {
+			fmt.Fprintf(
+				log,
+				"[%s]: \nTimestamp: %d\nAccX %f, AccY %f, AccZ %f, \nGyrX %f, GyrY %f, GyrZ %f\n",
+				tag, updateData.Timestamp,
+				updateData.AccX, updateData.AccY, updateData.AccZ,
+				updateData.GyrX, updateData.GyrY, updateData.GyrZ,
+			)
+		}
+
+		for axis := range 3 {
+			meanAcc[axis] += updateData.acc()[axis] / float64(samples)
+			meanGyr[axis] += updateData.gyr()[axis] / float64(samples)
+		}
+	}
+
+	magnitude := meanAcc.norm()
+	pitch, roll := tiltAngles(meanAcc)
 	fmt.Fprintf(
 		log,
-		"[Check Digital %s IMU]: \nTimestamp: %d\nAccX %f, AccY %f, AccZ %f, \nGyrX %f, GyrY %f, GyrZ %f\n",
-		digitalBoardVersion, updateData.Timestamp,
-		updateData.AccX, updateData.AccY, updateData.AccZ,
-		updateData.GyrX, updateData.GyrY, updateData.GyrZ,
+		"[%s]: Acc magnitude %.3f (expected %.3f), pitch %.1f°, roll %.1f°\nGyro bias %.4f, %.4f, %.4f\n",
+		tag, magnitude, expected.Gravity, pitch, roll, meanGyr[0], meanGyr[1], meanGyr[2],
 	)
 
-	return true
+	success := true
+	if math.Abs(magnitude-expected.Gravity) > expected.GravityTolerance {
+		fmt.Fprintf(log, "[%s]: Acc magnitude is off by more than %.3f\n", tag, expected.GravityTolerance)
+		success = false
+	}
+
+	if meanGyr.norm() > expected.MaxGyroBias {
+		fmt.Fprintf(log, "[%s]: Gyro bias %.4f is above %.4f\n", tag, meanGyr.norm(), expected.MaxGyroBias)
+		success = false
+	}
+
+	upAxis := vec3(expected.UpAxis)
+	if upAxis.norm() == 0 {
+		fmt.Fprintf(log, "[%s]: No rail orientation configured, skipping orientation check\n", tag)
+		return success
+	}
+
+	expectedPitch, expectedRoll := tiltAngles(upAxis)
+	angle := meanAcc.angleTo(upAxis)
+	fmt.Fprintf(
+		log, "[%s]: Expected pitch %.1f°, roll %.1f°, gravity is %.1f° away from expected (limit %.1f°)\n",
+		tag, expectedPitch, expectedRoll, angle, expected.ToleranceDeg,
+	)
+	if angle > expected.ToleranceDeg {
+		if angle > 135 {
+			fmt.Fprintf(log, "[%s]: IMU looks mounted upside down\n", tag)
+		} else if angle > 45 {
+			fmt.Fprintf(log, "[%s]: IMU axes look swapped\n", tag)
+		}
+		success = false
+	}
+
+	return success
 }
 
-type AltimeterData struct {
-	Temp      int32
-	Pressure  int32
+// standard sea level pressure, used until the altimeter is zeroed at the pad
+const STANDARD_PRESSURE_PA = 101325.0
+
+// altimeter reply converted to real units
+type AltimeterReading struct {
+	TempC       float64
+	PressurePa  float64
+	PressureHPa float64
+
+	// barometric altitude above the ground reference
+	AltitudeM   float64
+	ReferencePa float64
+
 	Timestamp uint32
 }
 
-func CheckDigitalAltimeterCommand(conn SerialReaderWriter, log io.Writer, digitalBoardVersion string, command byte) bool {
-	fmt.Fprintf(log, "[Check Digital %s Altimeter]: Entering inspect mode\n", digitalBoardVersion)
-	if !EnterInspectCommand(conn, log) {
-		fmt.Fprintf(log, "[Check Digital %s Altimeter]: Failed to enter inspect mode\n", digitalBoardVersion)
-		return false
+// international barometric formula, altitude of pressure relative to reference
+func barometricAltitude(pressurePa float64, referencePa float64) float64 {
+	return 44330.77 * (1 - math.Pow(pressurePa/referencePa, 0.190263))
+}
+
+// the zeroed reference for this session wins over the configured one
+func groundReference(conn SerialReaderWriter, configuredPa float64) float64 {
+	referencePa := configuredPa
+	withSession(conn, func(s *sessionState) {
+		if s.groundPressurePa != 0 {
+			referencePa = s.groundPressurePa
+		}
+	})
+	if referencePa == 0 {
+		referencePa = STANDARD_PRESSURE_PA
 	}
+	return referencePa
+}
 
-	sdUpdateMessage := getDispatchCommand(command)
-	conn.WriteSingleMessage(sdUpdateMessage[:], COMMAND_SEQUENCE_SIZE)
-	fmt.Fprintf(log, "[Chceck Digital %s Altimeter]: Sent command requesting Altimeter update\n", digitalBoardVersion)
+func SampleAltimeter(conn SerialReaderWriter, log io.Writer, digitalBoardVersion string, command byte, groundPressurePa float64) *AltimeterReading {
+	tag := fmt.Sprintf("Check Digital %s Altimeter", digitalBoardVersion)
 
-	res, err := conn.ReadSingleOrTimeout()
-	if err != nil {
-		fmt.Fprintf(log, "[Check Digital %s Altimeter]: Read timed out\n", digitalBoardVersion)
-		return false
+	fmt.Fprintf(log, "[%s]: Entering inspect mode\n", tag)
+	if !EnterInspectCommand(conn, log) {
+		fmt.Fprintf(log, "[%s]: Failed to enter inspect mode\n", tag)
+		return nil
 	}
-	fmt.Fprintf(log, "[Check Digital %s Altimeter]: Receieved response from boards\n", digitalBoardVersion)
-	streamReader := bytes.NewReader(res[:])
+
+	fmt.Fprintf(log, "[%s]: Sent command requesting Altimeter update\n", tag)
 	var updateData AltimeterData
-	if err := binary.Read(streamReader, binary.LittleEndian, &updateData); err != nil {
+	if _, ok := requestReading(conn, log, tag, command, &updateData); !ok {
+		return nil
+	}
+	fmt.Fprintf(log, "[%s]: Receieved response from boards\n", tag)
+
+	// the board reports hundredths of a degree and pascals
+	reading := &AltimeterReading{
+		TempC:       float64(updateData.Temp) / 100,
+		PressurePa:  float64(updateData.Pressure),
+		PressureHPa: float64(updateData.Pressure) / 100,
+		ReferencePa: groundReference(conn, groundPressurePa),
+		Timestamp:   updateData.Timestamp,
+	}
+	reading.AltitudeM = barometricAltitude(reading.PressurePa, reading.ReferencePa)
+
+	return reading
+}
+
+func CheckDigitalAltimeterCommand(conn SerialReaderWriter, log io.Writer, digitalBoardVersion string, command byte, groundPressurePa float64) bool {
+	reading := SampleAltimeter(conn, log, digitalBoardVersion, command, groundPressurePa)
+	if reading == nil {
 		return false
 	}
+
 	fmt.Fprintf(
 		log,
-		"[Check Digital %s Altimeter]: \nTimestamp: %d\nTemp: %f, Pressure: %f",
-		digitalBoardVersion, updateData.Timestamp,
-		float32(updateData.Temp)/100, float32(updateData.Pressure)/100,
+		"[Check Digital %s Altimeter]: \nTimestamp: %d\nTemp: %.2f °C, Pressure: %.0f Pa (%.2f hPa)\nAltitude: %.1f m above reference %.2f hPa\n",
+		digitalBoardVersion, reading.Timestamp,
+		reading.TempC, reading.PressurePa, reading.PressureHPa,
+		reading.AltitudeM, reading.ReferencePa/100,
 	)
 
 	return true
 }
 
-type GPSData struct {
-	Lat  int32
-	Long int32
+// record the current pressure as ground level for the rest of the session
+func ZeroAltimeter(conn SerialReaderWriter, log io.Writer, digitalBoardVersion string, command byte) bool {
+	reading := SampleAltimeter(conn, log, digitalBoardVersion, command, STANDARD_PRESSURE_PA)
+	if reading == nil {
+		return false
+	}
+
+	withSession(conn, func(s *sessionState) {
+		s.groundPressurePa = reading.PressurePa
+	})
+
+	fmt.Fprintf(
+		log,
+		"[Zero Digital %s Altimeter]: Ground reference set to %.2f hPa at %.2f °C\n",
+		digitalBoardVersion, reading.PressureHPa, reading.TempC,
+	)
+	return true
+}
+
+// the GPS reports coordinates in units of 1e-7 degrees
+const GPS_COORDINATE_SCALE = 1e-7
+
+// area the tracker is expected to report while on the pad, all zero disables the check
+type LaunchSiteBounds struct {
+	MinLat float64
+	MaxLat float64
+	MinLon float64
+	MaxLon float64
 }
 
-func CheckDigitalGPSCommand(conn SerialReaderWriter, log io.Writer, digitalBoardVersion string, command byte) bool {
-	fmt.Fprintf(log, "[Check Digital %s GPS]: Entering inspect mode\n", digitalBoardVersion)
-	if !EnterInspectCommand(conn, log) {
-		fmt.Fprintf(log, "[Check Digital %s GPS]: Failed to enter inspect mode\n", digitalBoardVersion)
-		return false
+func (b LaunchSiteBounds) configured() bool {
+	return b != LaunchSiteBounds{}
+}
+
+func (b LaunchSiteBounds) contains(lat float64, lon float64) bool {
+	return lat >= b.MinLat && lat <= b.MaxLat && lon >= b.MinLon && lon <= b.MaxLon
+}
+
+type GPSReading struct {
+	LatDeg float64
+	LonDeg float64
+}
+
+// degrees, minutes, seconds with the hemisphere letter, e.g. 35°20'50.12"N
+func formatDMS(deg float64, positive string, negative string) string {
+	hemisphere := positive
+	if deg < 0 {
+		hemisphere = negative
+		deg = -deg
 	}
+	// rounded to hundredths of a second first, so 59.999" carries into the minutes instead of printing 60.00"
+	hundredths := int64(math.Round(deg * 3600 * 100))
+	degrees := hundredths / (3600 * 100)
+	minutes := hundredths / (60 * 100) % 60
+	seconds := float64(hundredths%(60*100)) / 100
+	return fmt.Sprintf("%d°%02d'%05.2f\"%s", degrees, minutes, seconds, hemisphere)
+}
 
-	sdUpdateMessage := getDispatchCommand(command)
-	conn.WriteSingleMessage(sdUpdateMessage[:], COMMAND_SEQUENCE_SIZE)
-	fmt.Fprintf(log, "[Chceck Digital %s GPS]: Sent command requesting GPS update\n", digitalBoardVersion)
+func SampleGPS(conn SerialReaderWriter, log io.Writer, digitalBoardVersion string, command byte) *GPSReading {
+	tag := fmt.Sprintf("Check Digital %s GPS", digitalBoardVersion)
 
-	res, err := conn.ReadSingleOrTimeout()
-	if err != nil {
-		fmt.Fprintf(log, "[Check Digital %s GPS]: Read timed out\n", digitalBoardVersion)
-		return false
+	fmt.Fprintf(log, "[%s]: Entering inspect mode\n", tag)
+	if !EnterInspectCommand(conn, log) {
+		fmt.Fprintf(log, "[%s]: Failed to enter inspect mode\n", tag)
+		return nil
 	}
-	fmt.Fprintf(log, "[Check Digital %s GPS]: Receieved response from boards\n", digitalBoardVersion)
-	streamReader := bytes.NewReader(res[:])
+
+	fmt.Fprintf(log, "[%s]: Sent command requesting GPS update\n", tag)
 	var updateData GPSData
-	if err := binary.Read(streamReader, binary.LittleEndian, &updateData); err != nil {
+	if _, ok := requestReading(conn, log, tag, command, &updateData); !ok {
+		return nil
+	}
+	fmt.Fprintf(log, "[%s]: Receieved response from boards, raw Lat: %d Long: %d\n", tag, updateData.Lat, updateData.Long)
+
+	return &GPSReading{
+		LatDeg: float64(updateData.Lat) * GPS_COORDINATE_SCALE,
+		LonDeg: float64(updateData.Long) * GPS_COORDINATE_SCALE,
+	}
+}
+
+func CheckDigitalGPSCommand(conn SerialReaderWriter, log io.Writer, digitalBoardVersion string, command byte, site LaunchSiteBounds, exportDir string) bool {
+	reading := SampleGPS(conn, log, digitalBoardVersion, command)
+	if reading == nil {
 		return false
 	}
+
+	tag := fmt.Sprintf("Check Digital %s GPS", digitalBoardVersion)
 	fmt.Fprintf(
 		log,
-		"[Check Digital %s GPS]: Lat: %d Long: %d",
-		digitalBoardVersion,
-		updateData.Lat, updateData.Long,
+		"[%s]: Lat: %.7f Long: %.7f\n%s %s\n%s\n",
+		tag, reading.LatDeg, reading.LonDeg,
+		formatDMS(reading.LatDeg, "N", "S"), formatDMS(reading.LonDeg, "E", "W"),
+		mapLink(reading.LatDeg, reading.LonDeg),
 	)
 
+	// the receiver reports 0, 0 until it has a fix
+	if reading.LatDeg == 0 && reading.LonDeg == 0 {
+		fmt.Fprintf(log, "[%s]: No GPS fix\n", tag)
+		return false
+	}
+	if math.Abs(reading.LatDeg) > 90 || math.Abs(reading.LonDeg) > 180 {
+		fmt.Fprintf(log, "[%s]: Coordinates are out of range\n", tag)
+		return false
+	}
+
+	if !site.configured() {
+		fmt.Fprintf(log, "[%s]: No launch site configured, skipping location check\n", tag)
+	} else if !site.contains(reading.LatDeg, reading.LonDeg) {
+		// a fix somewhere else is not exported, it would only put a wrong pin in the recovery map
+		fmt.Fprintf(
+			log, "[%s]: Fix is outside the launch site (lat %.5f..%.5f, long %.5f..%.5f), not exporting a waypoint\n",
+			tag, site.MinLat, site.MaxLat, site.MinLon, site.MaxLon,
+		)
+		return false
+	} else {
+		fmt.Fprintf(log, "[%s]: Fix is inside the launch site\n", tag)
+	}
+
+	if paths, err := exportWaypoint(exportDir, fmt.Sprintf("Digital %s GPS fix", digitalBoardVersion), reading.LatDeg, reading.LonDeg, time.Now()); err != nil {
+		fmt.Fprintf(log, "[%s]: Could not export waypoint, %s\n", tag, err)
+	} else {
+		fmt.Fprintf(log, "[%s]: Waypoint written to %s\n", tag, strings.Join(paths, ", "))
+	}
+
 	return true
 }
@@ -0,0 +1,55 @@
+package commander
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBarometricAltitude(t *testing.T) {
+	// standard atmosphere pressures, the formula should agree with them to within a metre
+	tests := []struct {
+		name        string
+		pressurePa  float64
+		referencePa float64
+		altitudeM   float64
+	}{
+		{"at the reference", 101325, 101325, 0},
+		{"500 m", 95460.8, 101325, 500},
+		{"1000 m", 89874.6, 101325, 1000},
+		{"3000 m", 70108.5, 101325, 3000},
+		// only the ratio to the reference matters, so the same drop from a zeroed pad reads the same
+		{"zeroed on a high pad", 84673.09, 95460.8, 1000},
+		{"below the reference", 101325, 95460.8, -505.7},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			altitude := barometricAltitude(test.pressurePa, test.referencePa)
+			if math.Abs(altitude-test.altitudeM) > 1 {
+				t.Fatalf("altitude %.1f m, want %.0f m", altitude, test.altitudeM)
+			}
+		})
+	}
+}
+
+func TestFormatDMS(t *testing.T) {
+	tests := []struct {
+		name string
+		deg  float64
+		want string
+	}{
+		{"equator", 0, "0°00'00.00\"N"},
+		{"north", 35.347254, "35°20'50.11\"N"},
+		{"south", -33.8688, "33°52'07.68\"S"},
+		{"seconds round up into the minute", 35.3499999, "35°21'00.00\"N"},
+		{"minutes carry into the degree", 116.9999999, "117°00'00.00\"N"},
+		{"just below the carry", 116.9999972, "116°59'59.99\"N"},
+		{"southern carry", -117.8099999, "117°48'36.00\"S"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := formatDMS(test.deg, "N", "S"); got != test.want {
+				t.Fatalf("formatDMS(%v) = %s, want %s", test.deg, got, test.want)
+			}
+		})
+	}
+}
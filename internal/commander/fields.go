@@ -0,0 +1,49 @@
+package commander
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// flatten the numeric fields of a reading into name -> value, arrays become Name[0], Name[1], ...
+// the same names the raw inspector, alarm rules and recordings use for a reply
+func Fields(reading any) map[string]float64 {
+	fields := map[string]float64{}
+
+	v := reflect.Indirect(reflect.ValueOf(reading))
+	if v.Kind() != reflect.Struct {
+		return fields
+	}
+
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		value := v.Field(i)
+		if value.Kind() == reflect.Array {
+			for j := range value.Len() {
+				if number, ok := numericValue(value.Index(j)); ok {
+					fields[fmt.Sprintf("%s[%d]", field.Name, j)] = number
+				}
+			}
+		} else if number, ok := numericValue(value); ok {
+			fields[field.Name] = number
+		}
+	}
+
+	return fields
+}
+
+func numericValue(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
@@ -8,14 +8,6 @@ import (
 	"io"
 )
 
-type ModeTransitionErrorResponse struct {
-	Code    uint8
-	A1State uint8
-	A2State uint8
-	D1State uint8
-	D2State uint8
-}
-
 func EnterNormalCommand(conn SerialReaderWriter, log io.Writer) bool {
 	fmt.Fprintf(log, "[Enter Normal Command]: sending command to enter normal mode\n")
 
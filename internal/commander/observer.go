@@ -0,0 +1,93 @@
+package commander
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// a reply as an observer sees it, Decoded is nil when the reply did not decode
+type Reply struct {
+	Opcode     byte
+	SentAt     time.Time
+	ReceivedAt time.Time
+	Raw        []byte
+	Err        error
+	Decoded    *Decoded
+}
+
+// told about every frame sent and every reply read on a connection, e.g. metrics and alarms
+type Observer interface {
+	Sent(opcode byte, at time.Time)
+	Replied(reply Reply)
+}
+
+type ObservedConn struct {
+	conn      SerialReaderWriter
+	observers []Observer
+
+	mu     sync.Mutex
+	opcode byte
+	sentAt time.Time
+}
+
+func Observe(conn SerialReaderWriter, observers ...Observer) *ObservedConn {
+	return &ObservedConn{conn: conn, observers: observers}
+}
+
+func (c *ObservedConn) WriteSingleMessage(message []byte, size int) {
+	if size > 0 {
+		now := time.Now()
+		c.mu.Lock()
+		c.opcode = message[0]
+		c.sentAt = now
+		c.mu.Unlock()
+		for _, observer := range c.observers {
+			observer.Sent(message[0], now)
+		}
+	}
+	c.conn.WriteSingleMessage(message, size)
+}
+
+func (c *ObservedConn) ReadSingleOrTimeout() ([]byte, error) {
+	res, err := c.conn.ReadSingleOrTimeout()
+	return c.replied(res, err)
+}
+
+func (c *ObservedConn) ReadSingleWithin(timeout time.Duration) ([]byte, error) {
+	res, err := readWithin(c.conn, timeout)
+	return c.replied(res, err)
+}
+
+func (c *ObservedConn) replied(res []byte, err error) ([]byte, error) {
+	c.mu.Lock()
+	reply := Reply{Opcode: c.opcode, SentAt: c.sentAt, ReceivedAt: time.Now(), Raw: res, Err: err}
+	c.mu.Unlock()
+	if err == nil {
+		reply.Decoded, _ = DecodeResponse(reply.Opcode, res)
+	}
+
+	for _, observer := range c.observers {
+		observer.Replied(reply)
+	}
+	return res, err
+}
+
+// the connection being observed, e.g. to reach the frame reader of the port
+func (c *ObservedConn) Unwrap() SerialReaderWriter {
+	return c.conn
+}
+
+// releases the port underneath, e.g. when the server reconnects, and closes observers that hold files
+func (c *ObservedConn) Close() error {
+	forgetSession(c)
+	for _, observer := range c.observers {
+		if closer, ok := observer.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+	if closer, ok := c.conn.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,310 @@
+// Code generated by cmd/protogen from protocol/protocol.yaml. DO NOT EDIT.
+
+package commander
+
+import (
+	"UCLA-Rocket-Project/ILAYE/internal/globals"
+	"time"
+)
+
+// the opcode echoed back once a command is done
+type ackReply struct {
+	Code uint8
+}
+
+// board time after a jump or clock read
+type boardClockReply struct {
+	BoardMicros int64
+}
+
+// protocol version and firmware of every board, firmware is major << 8 | minor
+// a revision of 0 means that board did not answer
+type versionReply struct {
+	ProtocolVersion uint16
+	RadioFirmware   uint16
+	AnalogRevision  uint8
+	AnalogFirmware  uint16
+	DigitalRevision uint8
+	DigitalFirmware uint16
+}
+
+// payload of the jump clock command, followed by +++
+type jumpClockUplinkPaylod struct {
+	CommandCode            uint8
+	CurrentTimeStampMicros int64
+}
+
+// to verify that the SD card is working
+// 1. Check the current file size and the timestamp
+// 2. Return the system back to normal mode and wait for 10s
+// 3. Send the system back into inspect mode
+// 4. Check the new file size and timestamp, it should be bigger than the previous one
+type sdUpdate struct {
+	LastTimestamp int64
+	FileSize      uint32
+}
+
+// free space on the SD card after a clear, in MB
+type sdFreeSpaceReply struct {
+	FreeMB uint32
+}
+
+type ModeTransitionErrorResponse struct {
+	Code    uint8
+	A1State uint8
+	A2State uint8
+	D1State uint8
+	D2State uint8
+}
+
+// in front of every telemetry packet, the sequence number counts packets of
+// each type and wraps around, board time is in microseconds
+type telemetryHeader struct {
+	Type        uint8
+	Sequence    uint16
+	BoardMicros int64
+}
+
+type ptUpdate struct {
+	Ch [3]float32
+}
+
+type shockData struct {
+	AccX float32
+	AccY float32
+	AccZ float32
+}
+
+type IMUData struct {
+	AccX      float32
+	AccY      float32
+	AccZ      float32
+	GyrX      float32
+	GyrY      float32
+	GyrZ      float32
+	Timestamp uint32
+}
+
+type AltimeterData struct {
+	Temp      int32
+	Pressure  int32
+	Timestamp uint32
+}
+
+type GPSData struct {
+	Lat  int32
+	Long int32
+}
+
+// the struct a reply to opcode is decoded into
+func responseLayout(opcode byte) (any, bool) {
+	switch opcode {
+	case globals.CMD_ENTER_NORMAL, globals.CMD_ENTER_INSPECT, globals.CMD_TEST_SERIAL_CONN, globals.CMD_ENTER_LAUNCH_MODE:
+		return &ackReply{}, true
+	case globals.CMD_JUMP_CLK:
+		return &boardClockReply{}, true
+	case globals.CMD_GET_VERSION:
+		return &versionReply{}, true
+	case globals.CMD_GET_RADIO_SD_UPDATE, globals.CMD_GET_ANALOG_V1_SD_UPDATE, globals.CMD_GET_DIGITAL_V1_SD_UPDATE, globals.CMD_GET_ANALOG_V2_SD_UPDATE, globals.CMD_GET_DIGITAL_V2_SD_UPDATE:
+		return &sdUpdate{}, true
+	case globals.CMD_CLEAR_RADIO_SD, globals.CMD_CLEAR_ANALOG_V1_SD, globals.CMD_CLEAR_DIGITAL_V1_SD, globals.CMD_CLEAR_ANALOG_V2_SD, globals.CMD_CLEAR_DIGITAL_V2_SD:
+		return &sdFreeSpaceReply{}, true
+	case globals.CMD_GET_ANALOG_V1_PT_READING, globals.CMD_GET_ANALOG_V2_PT_READING:
+		return &ptUpdate{}, true
+	case globals.CMD_GET_DIGITAL_V1_ALTIMETER_READING, globals.CMD_GET_DIGITAL_V2_ALTIMETER_READING:
+		return &AltimeterData{}, true
+	case globals.CMD_GET_DIGITAL_V1_SHOCK_1_READING, globals.CMD_GET_DIGITAL_V2_SHOCK_1_READING, globals.CMD_GET_DIGITAL_V2_SHOCK_2_READING:
+		return &shockData{}, true
+	case globals.CMD_GET_DIGITAL_V1_IMU_READING, globals.CMD_GET_DIGITAL_V2_IMU_READING:
+		return &IMUData{}, true
+	case globals.CMD_GET_DIGITAL_V2_GPS_READING:
+		return &GPSData{}, true
+	}
+	return nil, false
+}
+
+// the struct a request frame for opcode carries, only for commands with a payload
+func requestLayout(opcode byte) (any, bool) {
+	switch opcode {
+	case globals.CMD_JUMP_CLK:
+		return &jumpClockUplinkPaylod{}, true
+	}
+	return nil, false
+}
+
+// every reply struct, for replies to opcodes that have no known layout yet
+func knownLayouts() []any {
+	return []any{
+		&ackReply{},
+		&boardClockReply{},
+		&versionReply{},
+		&jumpClockUplinkPaylod{},
+		&sdUpdate{},
+		&sdFreeSpaceReply{},
+		&ModeTransitionErrorResponse{},
+		&telemetryHeader{},
+		&ptUpdate{},
+		&shockData{},
+		&IMUData{},
+		&AltimeterData{},
+		&GPSData{},
+	}
+}
+
+// names of the error codes the radio replies with instead of the expected struct
+func ErrorCodeName(code byte) (string, bool) {
+	switch code {
+	case globals.CMD_TIMEOUT:
+		return "CMD_TIMEOUT", true
+	case globals.RADIO_COMMAND_NOT_RECOGNIZED:
+		return "RADIO_COMMAND_NOT_RECOGNIZED", true
+	case globals.RADIO_COMMAND_INCORRECT:
+		return "RADIO_COMMAND_INCORRECT", true
+	case globals.RADIO_START_OR_END_WRONG:
+		return "RADIO_START_OR_END_WRONG", true
+	case globals.CAN_RESPONSE_WRONG:
+		return "CAN_RESPONSE_WRONG", true
+	case globals.RADIO_COMMAND_ERROR:
+		return "RADIO_COMMAND_ERROR", true
+	}
+	return "", false
+}
+
+// the struct an error reply carries, a bare error code decodes as an ackReply
+func errorCodeLayout(code byte) any {
+	switch code {
+	case globals.CAN_RESPONSE_WRONG:
+		return &ModeTransitionErrorResponse{}
+	}
+	return &ackReply{}
+}
+
+// names of the opcodes sent to the radio and of the host actions
+func OpcodeName(opcode byte) (string, bool) {
+	switch opcode {
+	case globals.CMD_ENTER_NORMAL:
+		return "CMD_ENTER_NORMAL", true
+	case globals.CMD_ENTER_INSPECT:
+		return "CMD_ENTER_INSPECT", true
+	case globals.CMD_JUMP_CLK:
+		return "CMD_JUMP_CLK", true
+	case globals.CMD_TEST_SERIAL_CONN:
+		return "CMD_TEST_SERIAL_CONN", true
+	case globals.CMD_ENTER_LAUNCH_MODE:
+		return "CMD_ENTER_LAUNCH_MODE", true
+	case globals.CMD_GET_VERSION:
+		return "CMD_GET_VERSION", true
+	case globals.CMD_GET_RADIO_SD_UPDATE:
+		return "CMD_GET_RADIO_SD_UPDATE", true
+	case globals.CMD_CLEAR_RADIO_SD:
+		return "CMD_CLEAR_RADIO_SD", true
+	case globals.CMD_GET_ANALOG_V1_SD_UPDATE:
+		return "CMD_GET_ANALOG_V1_SD_UPDATE", true
+	case globals.CMD_GET_ANALOG_V1_PT_READING:
+		return "CMD_GET_ANALOG_V1_PT_READING", true
+	case globals.CMD_CLEAR_ANALOG_V1_SD:
+		return "CMD_CLEAR_ANALOG_V1_SD", true
+	case globals.CMD_GET_DIGITAL_V1_SD_UPDATE:
+		return "CMD_GET_DIGITAL_V1_SD_UPDATE", true
+	case globals.CMD_GET_DIGITAL_V1_ALTIMETER_READING:
+		return "CMD_GET_DIGITAL_V1_ALTIMETER_READING", true
+	case globals.CMD_GET_DIGITAL_V1_SHOCK_1_READING:
+		return "CMD_GET_DIGITAL_V1_SHOCK_1_READING", true
+	case globals.CMD_GET_DIGITAL_V1_IMU_READING:
+		return "CMD_GET_DIGITAL_V1_IMU_READING", true
+	case globals.CMD_CLEAR_DIGITAL_V1_SD:
+		return "CMD_CLEAR_DIGITAL_V1_SD", true
+	case globals.CMD_GET_ANALOG_V2_SD_UPDATE:
+		return "CMD_GET_ANALOG_V2_SD_UPDATE", true
+	case globals.CMD_GET_ANALOG_V2_PT_READING:
+		return "CMD_GET_ANALOG_V2_PT_READING", true
+	case globals.CMD_CLEAR_ANALOG_V2_SD:
+		return "CMD_CLEAR_ANALOG_V2_SD", true
+	case globals.CMD_GET_DIGITAL_V2_SD_UPDATE:
+		return "CMD_GET_DIGITAL_V2_SD_UPDATE", true
+	case globals.CMD_GET_DIGITAL_V2_ALTIMETER_READING:
+		return "CMD_GET_DIGITAL_V2_ALTIMETER_READING", true
+	case globals.CMD_GET_DIGITAL_V2_GPS_READING:
+		return "CMD_GET_DIGITAL_V2_GPS_READING", true
+	case globals.CMD_GET_DIGITAL_V2_SHOCK_1_READING:
+		return "CMD_GET_DIGITAL_V2_SHOCK_1_READING", true
+	case globals.CMD_GET_DIGITAL_V2_SHOCK_2_READING:
+		return "CMD_GET_DIGITAL_V2_SHOCK_2_READING", true
+	case globals.CMD_GET_DIGITAL_V2_IMU_READING:
+		return "CMD_GET_DIGITAL_V2_IMU_READING", true
+	case globals.CMD_CLEAR_DIGITAL_V2_SD:
+		return "CMD_CLEAR_DIGITAL_V2_SD", true
+	case globals.HOST_CLOCK_DRIFT_TEST:
+		return "HOST_CLOCK_DRIFT_TEST", true
+	case globals.HOST_TIMESTAMP_CHECK:
+		return "HOST_TIMESTAMP_CHECK", true
+	case globals.HOST_SHOCK_CROSS_CHECK:
+		return "HOST_SHOCK_CROSS_CHECK", true
+	case globals.HOST_SD_CAPACITY_PLAN:
+		return "HOST_SD_CAPACITY_PLAN", true
+	case globals.HOST_ZERO_DIGITAL_V1_ALTIMETER:
+		return "HOST_ZERO_DIGITAL_V1_ALTIMETER", true
+	case globals.HOST_ZERO_DIGITAL_V2_ALTIMETER:
+		return "HOST_ZERO_DIGITAL_V2_ALTIMETER", true
+	}
+	return "", false
+}
+
+// the board that has to be fitted for opcode to work and its hardware revision,
+// an empty board means the radio or every revision
+func OpcodeBoard(opcode byte) (string, uint8) {
+	switch opcode {
+	case globals.CMD_GET_ANALOG_V1_SD_UPDATE, globals.CMD_GET_ANALOG_V1_PT_READING, globals.CMD_CLEAR_ANALOG_V1_SD:
+		return "analog", 1
+	case globals.CMD_GET_DIGITAL_V1_SD_UPDATE, globals.CMD_GET_DIGITAL_V1_ALTIMETER_READING, globals.CMD_GET_DIGITAL_V1_SHOCK_1_READING, globals.CMD_GET_DIGITAL_V1_IMU_READING, globals.CMD_CLEAR_DIGITAL_V1_SD, globals.HOST_ZERO_DIGITAL_V1_ALTIMETER:
+		return "digital", 1
+	case globals.CMD_GET_ANALOG_V2_SD_UPDATE, globals.CMD_GET_ANALOG_V2_PT_READING, globals.CMD_CLEAR_ANALOG_V2_SD:
+		return "analog", 2
+	case globals.CMD_GET_DIGITAL_V2_SD_UPDATE, globals.CMD_GET_DIGITAL_V2_ALTIMETER_READING, globals.CMD_GET_DIGITAL_V2_GPS_READING, globals.CMD_GET_DIGITAL_V2_SHOCK_1_READING, globals.CMD_GET_DIGITAL_V2_SHOCK_2_READING, globals.CMD_GET_DIGITAL_V2_IMU_READING, globals.CMD_CLEAR_DIGITAL_V2_SD, globals.HOST_SHOCK_CROSS_CHECK, globals.HOST_ZERO_DIGITAL_V2_ALTIMETER:
+		return "digital", 2
+	}
+	return "", 0
+}
+
+// tick of the 32 bit sensor timestamp in the reply to opcode and whether it was confirmed against
+// the firmware, ok is false when the protocol does not give one
+func sensorTimestampUnit(opcode byte) (unit time.Duration, confirmed bool, ok bool) {
+	switch opcode {
+	case globals.CMD_GET_DIGITAL_V1_SD_UPDATE, globals.CMD_GET_DIGITAL_V1_ALTIMETER_READING, globals.CMD_GET_DIGITAL_V1_SHOCK_1_READING, globals.CMD_GET_DIGITAL_V1_IMU_READING, globals.CMD_CLEAR_DIGITAL_V1_SD:
+		return time.Millisecond, false, true
+	case globals.CMD_GET_DIGITAL_V2_SD_UPDATE, globals.CMD_GET_DIGITAL_V2_ALTIMETER_READING, globals.CMD_GET_DIGITAL_V2_GPS_READING, globals.CMD_GET_DIGITAL_V2_SHOCK_1_READING, globals.CMD_GET_DIGITAL_V2_SHOCK_2_READING, globals.CMD_GET_DIGITAL_V2_IMU_READING, globals.CMD_CLEAR_DIGITAL_V2_SD:
+		return time.Millisecond, false, true
+	}
+	return 0, false, false
+}
+
+// the struct after the header of a telemetry packet, its name in the schema and the board that streams it
+func telemetryLayout(packetType byte) (any, string, string, bool) {
+	switch packetType {
+	case globals.TLM_ANALOG_PT:
+		return &ptUpdate{}, "TLM_ANALOG_PT", "analog", true
+	case globals.TLM_DIGITAL_ALTIMETER:
+		return &AltimeterData{}, "TLM_DIGITAL_ALTIMETER", "digital", true
+	case globals.TLM_DIGITAL_IMU:
+		return &IMUData{}, "TLM_DIGITAL_IMU", "digital", true
+	case globals.TLM_DIGITAL_GPS:
+		return &GPSData{}, "TLM_DIGITAL_GPS", "digital", true
+	case globals.TLM_DIGITAL_SHOCK_1:
+		return &shockData{}, "TLM_DIGITAL_SHOCK_1", "digital", true
+	case globals.TLM_DIGITAL_SHOCK_2:
+		return &shockData{}, "TLM_DIGITAL_SHOCK_2", "digital", true
+	}
+	return nil, "", "", false
+}
+
+// every telemetry type, in schema order
+func TelemetryTypes() []byte {
+	return []byte{
+		globals.TLM_ANALOG_PT,
+		globals.TLM_DIGITAL_ALTIMETER,
+		globals.TLM_DIGITAL_IMU,
+		globals.TLM_DIGITAL_GPS,
+		globals.TLM_DIGITAL_SHOCK_1,
+		globals.TLM_DIGITAL_SHOCK_2,
+	}
+}
@@ -0,0 +1,103 @@
+package commander
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// how many exchanges a recorder keeps, older ones are dropped first
+const MAX_RECORDED_EXCHANGES = 500
+
+// one frame sent and the reply read after it, either side may be missing
+type Exchange struct {
+	Sent   []byte
+	SentAt time.Time
+
+	Received   []byte
+	ReceivedAt time.Time
+	Err        error
+}
+
+// the opcode of the frame that was sent, 0 when the exchange only has a reply
+func (e Exchange) Opcode() byte {
+	if len(e.Sent) == 0 {
+		return 0
+	}
+	return e.Sent[0]
+}
+
+// wraps a connection and keeps a copy of every frame that goes over it, for the raw inspector
+type Recorder struct {
+	conn SerialReaderWriter
+
+	mu        sync.Mutex
+	exchanges []Exchange
+}
+
+func NewRecorder(conn SerialReaderWriter) *Recorder {
+	return &Recorder{conn: conn}
+}
+
+func (r *Recorder) record(exchange Exchange) {
+	r.exchanges = append(r.exchanges, exchange)
+	if len(r.exchanges) > MAX_RECORDED_EXCHANGES {
+		r.exchanges = r.exchanges[len(r.exchanges)-MAX_RECORDED_EXCHANGES:]
+	}
+}
+
+func (r *Recorder) WriteSingleMessage(message []byte, size int) {
+	r.mu.Lock()
+	r.record(Exchange{Sent: append([]byte{}, message[:size]...), SentAt: time.Now()})
+	r.mu.Unlock()
+
+	r.conn.WriteSingleMessage(message, size)
+}
+
+func (r *Recorder) ReadSingleOrTimeout() ([]byte, error) {
+	res, err := r.conn.ReadSingleOrTimeout()
+	return r.received(res, err)
+}
+
+func (r *Recorder) ReadSingleWithin(timeout time.Duration) ([]byte, error) {
+	res, err := readWithin(r.conn, timeout)
+	return r.received(res, err)
+}
+
+func (r *Recorder) received(res []byte, err error) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// pair the reply with the last frame sent, unless that one already has a reply
+	last := len(r.exchanges) - 1
+	if last < 0 || !r.exchanges[last].ReceivedAt.IsZero() {
+		r.record(Exchange{})
+		last = len(r.exchanges) - 1
+	}
+	r.exchanges[last].Received = append([]byte{}, res...)
+	r.exchanges[last].ReceivedAt = time.Now()
+	r.exchanges[last].Err = err
+
+	return res, err
+}
+
+// copy of the recorded exchanges, oldest first
+func (r *Recorder) Exchanges() []Exchange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Exchange{}, r.exchanges...)
+}
+
+// the connection being recorded
+func (r *Recorder) Unwrap() SerialReaderWriter {
+	return r.conn
+}
+
+// releases the port underneath, e.g. when its tab is closed
+func (r *Recorder) Close() error {
+	forgetSession(r)
+	if closer, ok := r.conn.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
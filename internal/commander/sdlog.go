@@ -0,0 +1,57 @@
+package commander
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// the reading structs a board revision answers with, e.g. IMUData and AltimeterData for digital 2,
+// taken from the *_READING commands of its group in the protocol
+func SDRecordLayouts(board string, revision uint8) []string {
+	layouts := []string{}
+	for opcode := range 256 {
+		name, ok := OpcodeName(byte(opcode))
+		if !ok || !strings.HasSuffix(name, "_READING") {
+			continue
+		}
+		if b, r := OpcodeBoard(byte(opcode)); b != board || r != revision {
+			continue
+		}
+		layout, _ := responseLayout(byte(opcode))
+		if layoutName := reflect.TypeOf(layout).Elem().Name(); !slices.Contains(layouts, layoutName) {
+			layouts = append(layouts, layoutName)
+		}
+	}
+	return layouts
+}
+
+// a fresh struct called name, matched without case so imudata finds IMUData
+func sdRecordLayout(name string) (any, bool) {
+	for _, layout := range knownLayouts() {
+		if strings.EqualFold(reflect.TypeOf(layout).Elem().Name(), name) {
+			return layout, true
+		}
+	}
+	return nil, false
+}
+
+// bytes of one record of the struct called name
+func SDRecordSize(name string) (int, error) {
+	layout, ok := sdRecordLayout(name)
+	if !ok {
+		return 0, fmt.Errorf("unknown struct %s", name)
+	}
+	return binary.Size(layout), nil
+}
+
+// one record of the struct called name, field by field
+func DecodeSDRecord(name string, raw []byte) (*Decoded, error) {
+	layout, ok := sdRecordLayout(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown struct %s", name)
+	}
+	return decodeInto(layout, raw)
+}
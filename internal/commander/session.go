@@ -0,0 +1,45 @@
+package commander
+
+import (
+	"sync"
+	"time"
+)
+
+// state that has to outlive a single command, e.g. when the clocks were last jumped
+// kept per connection so that two uplinkers never share results
+type sessionState struct {
+	lastJump       time.Time
+	lastJumpOffset time.Duration
+
+	// pressure recorded by the last "zero altimeter", 0 when never zeroed
+	groundPressurePa float64
+
+	// per board SD free space from the last clear, and logging rate from the last SD check
+	sdFreeMB      map[string]uint32
+	sdBytesPerSec map[string]float64
+}
+
+var sessionsMu sync.Mutex
+var sessions = map[SerialReaderWriter]*sessionState{}
+
+func withSession(conn SerialReaderWriter, fn func(s *sessionState)) {
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+
+	s, ok := sessions[conn]
+	if !ok {
+		s = &sessionState{
+			sdFreeMB:      map[string]uint32{},
+			sdBytesPerSec: map[string]float64{},
+		}
+		sessions[conn] = s
+	}
+	fn(s)
+}
+
+// called when a connection closes, the next one on the same port starts with a fresh session
+func forgetSession(conn SerialReaderWriter) {
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+	delete(sessions, conn)
+}
@@ -0,0 +1,87 @@
+package commander
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// told about every telemetry packet a stream decodes, e.g. the alarm engine
+type TelemetryObserver interface {
+	Packet(receivedAt time.Time, packet *TelemetryPacket)
+}
+
+// a packet the boards streamed on their own, decoded into the struct the checks use
+type TelemetryPacket struct {
+	Type        byte
+	Name        string
+	Board       string
+	Sequence    uint16
+	BoardMicros int64
+
+	// e.g. *IMUData, and the same bytes listed field by field
+	Data    any
+	Decoded *Decoded
+}
+
+// whether raw is a known telemetry type of the right size, checked before a frame is taken as a reply
+func IsTelemetry(raw []byte) bool {
+	if len(raw) == 0 {
+		return false
+	}
+	layout, _, _, ok := telemetryLayout(raw[0])
+	return ok && len(raw) == binary.Size(&telemetryHeader{})+binary.Size(layout)
+}
+
+// whether a telemetry packet carries the same reading as the reply to opcode, the struct
+// and the board have to match, any revision of the board does since packets do not say which
+func TelemetryCarries(packetType byte, opcode byte) bool {
+	packetLayout, _, packetBoard, ok := telemetryLayout(packetType)
+	if !ok {
+		return false
+	}
+	replyLayout, ok := responseLayout(opcode)
+	if !ok {
+		return false
+	}
+	board, _ := OpcodeBoard(opcode)
+	return board == packetBoard && reflect.TypeOf(packetLayout) == reflect.TypeOf(replyLayout)
+}
+
+func DecodeTelemetry(raw []byte) (*TelemetryPacket, error) {
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("empty frame")
+	}
+	layout, name, board, ok := telemetryLayout(raw[0])
+	if !ok {
+		return nil, fmt.Errorf("unknown telemetry type 0x%02X", raw[0])
+	}
+
+	var header telemetryHeader
+	headerSize := binary.Size(&header)
+	if want := headerSize + binary.Size(layout); len(raw) != want {
+		return nil, fmt.Errorf("%s is %d bytes, expected %d", name, len(raw), want)
+	}
+	if err := binary.Read(bytes.NewReader(raw), binary.LittleEndian, &header); err != nil {
+		return nil, err
+	}
+	if err := binary.Read(bytes.NewReader(raw[headerSize:]), binary.LittleEndian, layout); err != nil {
+		return nil, err
+	}
+	decoded, err := decodeInto(layout, raw[headerSize:])
+	if err != nil {
+		return nil, err
+	}
+
+	return &TelemetryPacket{
+		Type:        header.Type,
+		Name:        name,
+		Board:       board,
+		Sequence:    header.Sequence,
+		BoardMicros: header.BoardMicros,
+		Data:        layout,
+		Decoded:     decoded,
+	}, nil
+}
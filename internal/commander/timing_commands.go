@@ -0,0 +1,171 @@
+package commander
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// delay between the two samples taken from every source
+const TIMING_SAMPLE_GAP = 1 * time.Second
+
+type TimestampKind int
+
+const (
+	TIMESTAMP_SD_UPDATE TimestampKind = iota
+	TIMESTAMP_IMU
+	TIMESTAMP_ALTIMETER
+)
+
+// a reply that carries a board timestamp, and how to decode it
+type TimingSource struct {
+	Name    string
+	Command byte
+	Kind    TimestampKind
+}
+
+type timingSample struct {
+	hostTime  time.Time
+	boardTime time.Time
+
+	// false while the tick of a sensor timestamp is a guess, what it shows is then only a warning
+	confirmed bool
+}
+
+// sensor structs only carry the low 32 bits of the board clock, counted in unit ticks,
+// turn one into an absolute time assuming it lies within 2^31 ticks of the host reference
+func unwrapSensorTimestamp(ts uint32, unit time.Duration, reference time.Time) time.Time {
+	expected := uint32(reference.UnixNano() / int64(unit))
+	diff := int32(ts - expected)
+	return reference.Add(time.Duration(diff) * unit)
+}
+
+func sampleTimestamp(conn SerialReaderWriter, log io.Writer, test string, source TimingSource) (*timingSample, bool) {
+	tag := fmt.Sprintf("%s %s", test, source.Name)
+
+	unit, confirmed, knownUnit := sensorTimestampUnit(source.Command)
+	if source.Kind != TIMESTAMP_SD_UPDATE && !knownUnit {
+		fmt.Fprintf(log, "[%s]: protocol.yaml gives no timestamp_unit for this board\n", tag)
+		return nil, false
+	}
+
+	switch source.Kind {
+	case TIMESTAMP_SD_UPDATE:
+		var data sdUpdate
+		hostTime, ok := requestReading(conn, log, tag, source.Command, &data)
+		if !ok {
+			return nil, false
+		}
+		return &timingSample{hostTime: hostTime, boardTime: time.UnixMicro(data.LastTimestamp), confirmed: true}, true
+	case TIMESTAMP_IMU:
+		var data IMUData
+		hostTime, ok := requestReading(conn, log, tag, source.Command, &data)
+		if !ok {
+			return nil, false
+		}
+		return &timingSample{hostTime: hostTime, boardTime: unwrapSensorTimestamp(data.Timestamp, unit, hostTime), confirmed: confirmed}, true
+	case TIMESTAMP_ALTIMETER:
+		var data AltimeterData
+		hostTime, ok := requestReading(conn, log, tag, source.Command, &data)
+		if !ok {
+			return nil, false
+		}
+		return &timingSample{hostTime: hostTime, boardTime: unwrapSensorTimestamp(data.Timestamp, unit, hostTime), confirmed: confirmed}, true
+	}
+
+	return nil, false
+}
+
+// sample every source twice and check that the board timestamps move forward, were taken
+// after the last clock jump and agree with each other. An SD card that logged nothing since
+// the jump is only a warning, Jump Clock leaves the boards in inspect mode where they stop logging
+func CheckBoardTimestamps(conn SerialReaderWriter, log io.Writer, sources []TimingSource, tolerance time.Duration) bool {
+	fmt.Fprintf(log, "[Timing Check]: Entering inspect mode\n")
+	if !EnterInspectCommand(conn, log) {
+		fmt.Fprintf(log, "[Timing Check]: Failed to enter inspect mode\n")
+		return false
+	}
+
+	var lastJump time.Time
+	withSession(conn, func(s *sessionState) {
+		lastJump = s.lastJump
+	})
+
+	success := true
+	if lastJump.IsZero() {
+		fmt.Fprintf(log, "[Timing Check]: Clocks have not been jumped this session, run Jump Clock first\n")
+		success = false
+	}
+
+	firstSamples := make([]*timingSample, len(sources))
+	secondSamples := make([]*timingSample, len(sources))
+	for i, source := range sources {
+		firstSamples[i], _ = sampleTimestamp(conn, log, "Timing Check", source)
+	}
+	time.Sleep(TIMING_SAMPLE_GAP)
+	for i, source := range sources {
+		secondSamples[i], _ = sampleTimestamp(conn, log, "Timing Check", source)
+	}
+
+	// a problem shown by a timestamp whose unit is a guess is reported without failing the check
+	problem := func(confirmed bool, format string, args ...any) {
+		if confirmed {
+			fmt.Fprintf(log, "[Timing Check]: "+format+"\n", args...)
+			success = false
+			return
+		}
+		fmt.Fprintf(log, "[Timing Check]: WARNING "+format+" (timestamp unit not confirmed against the firmware)\n", args...)
+	}
+
+	// live sensor offsets are compared against each other at the end
+	var minOffset, maxOffset time.Duration
+	var minName, maxName string
+	minConfirmed, maxConfirmed := true, true
+	for i, source := range sources {
+		first, second := firstSamples[i], secondSamples[i]
+		if first == nil || second == nil {
+			fmt.Fprintf(log, "[Timing Check]: %s: could not sample timestamps\n", source.Name)
+			success = false
+			continue
+		}
+
+		offset := second.boardTime.Sub(second.hostTime)
+		fmt.Fprintf(
+			log, "[Timing Check]: %s: board time %s, offset from host %s\n",
+			source.Name, second.boardTime.Format("15:04:05.000"), offset,
+		)
+
+		if second.boardTime.Before(first.boardTime) {
+			problem(second.confirmed, "%s: timestamp went backwards by %s, board may have reset", source.Name, first.boardTime.Sub(second.boardTime))
+		}
+
+		if second.boardTime.After(second.hostTime.Add(tolerance)) {
+			problem(second.confirmed, "%s: timestamp is %s ahead of the host", source.Name, offset)
+		}
+
+		if !lastJump.IsZero() && second.boardTime.Before(lastJump.Add(-tolerance)) {
+			if source.Kind == TIMESTAMP_SD_UPDATE {
+				fmt.Fprintf(log, "[Timing Check]: WARNING %s: nothing logged since the last clock jump, expected while the boards are in inspect mode\n", source.Name)
+			} else {
+				problem(second.confirmed, "%s: timestamp predates the last clock jump, clock was never jumped or has reset", source.Name)
+			}
+		}
+
+		// the SD timestamp is the last record written, which stops moving in inspect mode
+		if source.Kind == TIMESTAMP_SD_UPDATE {
+			continue
+		}
+		if minName == "" || offset < minOffset {
+			minOffset, minName, minConfirmed = offset, source.Name, second.confirmed
+		}
+		if maxName == "" || offset > maxOffset {
+			maxOffset, maxName, maxConfirmed = offset, source.Name, second.confirmed
+		}
+	}
+
+	if minName != "" && maxOffset-minOffset > tolerance {
+		problem(minConfirmed && maxConfirmed, "%s and %s disagree by %s (limit %s)", maxName, minName, maxOffset-minOffset, tolerance)
+	}
+
+	return success
+}
@@ -0,0 +1,35 @@
+package commander
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUnwrapSensorTimestamp(t *testing.T) {
+	// 409 full turns of a 32 bit millisecond counter lands in August 2025
+	const turn = int64(1) << 32
+	ms := func(ticks int64) time.Time { return time.UnixMilli(409*turn + ticks) }
+
+	tests := []struct {
+		name      string
+		ts        uint32
+		unit      time.Duration
+		reference time.Time
+		want      time.Time
+	}{
+		{"same tick", 1000, time.Millisecond, ms(1000), ms(1000)},
+		{"behind the host", 500, time.Millisecond, ms(1000), ms(500)},
+		{"ahead of the host", 3000, time.Millisecond, ms(1000), ms(3000)},
+		{"counter wrapped since the reading", uint32(turn - 50), time.Millisecond, ms(100), ms(-50)},
+		{"counter wrapped after the reading", 50, time.Millisecond, ms(turn - 100), ms(turn + 50)},
+		{"finer unit", 1_000_250, 10 * time.Microsecond, time.Unix(0, 1_000_000*int64(10*time.Microsecond)), time.Unix(0, 1_000_250*int64(10*time.Microsecond))},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := unwrapSensorTimestamp(test.ts, test.unit, test.reference)
+			if !got.Equal(test.want) {
+				t.Fatalf("unwrapped to %s, want %s (off by %s)", got, test.want, got.Sub(test.want))
+			}
+		})
+	}
+}
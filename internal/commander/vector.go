@@ -0,0 +1,52 @@
+package commander
+
+import "math"
+
+type vec3 [3]float64
+
+func (v vec3) dot(o vec3) float64 {
+	return v[0]*o[0] + v[1]*o[1] + v[2]*o[2]
+}
+
+func (v vec3) norm() float64 {
+	return math.Sqrt(v.dot(v))
+}
+
+func (v vec3) sub(o vec3) vec3 {
+	return vec3{v[0] - o[0], v[1] - o[1], v[2] - o[2]}
+}
+
+func (v vec3) scale(k float64) vec3 {
+	return vec3{v[0] * k, v[1] * k, v[2] * k}
+}
+
+// angle between two vectors in degrees, 0 if either is zero
+func (v vec3) angleTo(o vec3) float64 {
+	n := v.norm() * o.norm()
+	if n == 0 {
+		return 0
+	}
+	cos := math.Max(-1, math.Min(1, v.dot(o)/n))
+	return math.Acos(cos) * 180 / math.Pi
+}
+
+// tilt of the gravity vector, as an accelerometer at rest would report it
+func tiltAngles(acc vec3) (pitchDeg float64, rollDeg float64) {
+	pitchDeg = math.Atan2(-acc[0], math.Hypot(acc[1], acc[2])) * 180 / math.Pi
+	rollDeg = math.Atan2(acc[1], acc[2]) * 180 / math.Pi
+	return pitchDeg, rollDeg
+}
+
+type mat3 [3][3]float64
+
+func (m mat3) isZero() bool {
+	return m == mat3{}
+}
+
+func (m mat3) mul(v vec3) vec3 {
+	return vec3{
+		m[0][0]*v[0] + m[0][1]*v[1] + m[0][2]*v[2],
+		m[1][0]*v[0] + m[1][1]*v[1] + m[1][2]*v[2],
+		m[2][0]*v[0] + m[2][1]*v[1] + m[2][2]*v[2],
+	}
+}
@@ -0,0 +1,139 @@
+package commander
+
+import (
+	"UCLA-Rocket-Project/ILAYE/internal/globals"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// how long the version query is waited on before the versions are treated as unknown
+const VERSION_QUERY_TIMEOUT = 2 * time.Second
+
+// major in the high byte, minor in the low byte, as the firmware reports it
+type FirmwareVersion uint16
+
+func (v FirmwareVersion) String() string {
+	return fmt.Sprintf("%d.%d", v>>8, v&0xFF)
+}
+
+func (v FirmwareVersion) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// "1.4" -> 0x0104, an empty string is 0.0 and accepts any firmware
+func ParseFirmwareVersion(s string) (FirmwareVersion, error) {
+	if s == "" {
+		return 0, nil
+	}
+	majorText, minorText, _ := strings.Cut(s, ".")
+	major, err := strconv.ParseUint(majorText, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("firmware version %q is not major.minor", s)
+	}
+	minor := uint64(0)
+	if minorText != "" {
+		if minor, err = strconv.ParseUint(minorText, 10, 8); err != nil {
+			return 0, fmt.Errorf("firmware version %q is not major.minor", s)
+		}
+	}
+	return FirmwareVersion(major<<8 | minor), nil
+}
+
+// what the radio reported in the version handshake
+type BoardVersions struct {
+	Protocol uint16
+	Radio    FirmwareVersion
+
+	// hardware revision of each board, 0 when it did not answer
+	AnalogRevision  uint8
+	Analog          FirmwareVersion
+	DigitalRevision uint8
+	Digital         FirmwareVersion
+}
+
+func (v BoardVersions) String() string {
+	board := func(revision uint8, firmware FirmwareVersion) string {
+		if revision == 0 {
+			return "not fitted"
+		}
+		return fmt.Sprintf("V%d firmware %s", revision, firmware)
+	}
+	return fmt.Sprintf(
+		"protocol %d, radio firmware %s, analog %s, digital %s",
+		v.Protocol, v.Radio, board(v.AnalogRevision, v.Analog), board(v.DigitalRevision, v.Digital),
+	)
+}
+
+// whether the board revision an opcode needs is fitted
+func (v BoardVersions) Supports(opcode byte) bool {
+	board, revision := OpcodeBoard(opcode)
+	switch board {
+	case "analog":
+		return v.AnalogRevision == revision
+	case "digital":
+		return v.DigitalRevision == revision
+	}
+	return true
+}
+
+// oldest firmware accepted on each board, 0 accepts anything
+type VersionRequirements struct {
+	Radio   FirmwareVersion
+	Analog  FirmwareVersion
+	Digital FirmwareVersion
+}
+
+func QueryVersions(conn SerialReaderWriter, log io.Writer) *BoardVersions {
+	fmt.Fprintf(log, "[Version]: Requesting protocol and firmware versions\n")
+
+	// firmware from before the handshake does not know the opcode, so it is not waited on for the full read timeout
+	cmd := getDispatchCommand(globals.CMD_GET_VERSION)
+	conn.WriteSingleMessage(cmd[:], COMMAND_SEQUENCE_SIZE)
+	res, err := readWithin(conn, VERSION_QUERY_TIMEOUT)
+	if err != nil {
+		fmt.Fprintf(log, "[Version]: No reply within %s, version unknown\n", VERSION_QUERY_TIMEOUT)
+		return nil
+	}
+
+	var reply versionReply
+	if err := binary.Read(bytes.NewReader(res), binary.LittleEndian, &reply); err != nil {
+		fmt.Fprintf(log, "[Version]: Reply is not a version reply, version unknown\n")
+		return nil
+	}
+
+	versions := &BoardVersions{
+		Protocol:        reply.ProtocolVersion,
+		Radio:           FirmwareVersion(reply.RadioFirmware),
+		AnalogRevision:  reply.AnalogRevision,
+		Analog:          FirmwareVersion(reply.AnalogFirmware),
+		DigitalRevision: reply.DigitalRevision,
+		Digital:         FirmwareVersion(reply.DigitalFirmware),
+	}
+	fmt.Fprintf(log, "[Version]: %s\n", versions)
+	return versions
+}
+
+// a protocol mismatch is refused, firmware older than required is only a warning
+// since the checks themselves will show whether the board still behaves
+func (v BoardVersions) Check(log io.Writer, required VersionRequirements) bool {
+	if v.Protocol != globals.PROTOCOL_VERSION {
+		fmt.Fprintf(log, "[Version]: Radio speaks protocol %d but ILAYE speaks %d, refusing to send commands\n", v.Protocol, globals.PROTOCOL_VERSION)
+		return false
+	}
+
+	warn := func(board string, fitted bool, actual FirmwareVersion, minimum FirmwareVersion) {
+		if fitted && actual < minimum {
+			fmt.Fprintf(log, "[Version]: WARNING %s firmware %s is older than the required %s\n", board, actual, minimum)
+		}
+	}
+	warn("Radio", true, v.Radio, required.Radio)
+	warn("Analog", v.AnalogRevision != 0, v.Analog, required.Analog)
+	warn("Digital", v.DigitalRevision != 0, v.Digital, required.Digital)
+
+	return true
+}
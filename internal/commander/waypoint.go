@@ -0,0 +1,60 @@
+package commander
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func mapLink(lat float64, lon float64) string {
+	return fmt.Sprintf("https://www.openstreetmap.org/?mlat=%.7f&mlon=%.7f#map=17/%.7f/%.7f", lat, lon, lat, lon)
+}
+
+func escapeXML(text string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(text))
+	return b.String()
+}
+
+// write the fix as both a GPX and a KML waypoint so recovery can load it in whatever app they have
+func exportWaypoint(dir string, name string, lat float64, lon float64, at time.Time) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	base := filepath.Join(dir, "gps_fix_"+at.Format("20060102-150405"))
+	escapedName := escapeXML(name)
+
+	gpx := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="ILAYE" xmlns="http://www.topografix.com/GPX/1/1">
+  <wpt lat="%.7f" lon="%.7f">
+    <time>%s</time>
+    <name>%s</name>
+  </wpt>
+</gpx>
+`, lat, lon, at.UTC().Format(time.RFC3339), escapedName)
+
+	// KML puts longitude first
+	kml := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<kml xmlns="http://www.opengis.net/kml/2.2">
+  <Placemark>
+    <name>%s</name>
+    <TimeStamp><when>%s</when></TimeStamp>
+    <Point><coordinates>%.7f,%.7f,0</coordinates></Point>
+  </Placemark>
+</kml>
+`, escapedName, at.UTC().Format(time.RFC3339), lon, lat)
+
+	paths := []string{base + ".gpx", base + ".kml"}
+	if err := os.WriteFile(paths[0], []byte(gpx), 0644); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(paths[1], []byte(kml), 0644); err != nil {
+		return nil, err
+	}
+
+	return paths, nil
+}
@@ -0,0 +1,328 @@
+/**
+Operator configuration for ILAYE
+
+Everything that depends on the vehicle or the launch site (limits, thresholds,
+timing windows) lives here so it can be changed at the pad without a rebuild.
+Any field left out of the file keeps its default value.
+*/
+
+package config
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Config struct {
+	Clock     ClockConfig     `yaml:"clock"`
+	Altimeter AltimeterConfig `yaml:"altimeter"`
+	GPS       GPSConfig       `yaml:"gps"`
+	IMU       IMUConfig       `yaml:"imu"`
+	SD        SDConfig        `yaml:"sd"`
+	Mission   MissionConfig   `yaml:"mission"`
+	Checklist ChecklistConfig `yaml:"checklist"`
+	Versions  VersionsConfig  `yaml:"versions"`
+	Server    ServerConfig    `yaml:"server"`
+	Bridge    BridgeConfig    `yaml:"bridge"`
+	Metrics   MetricsConfig   `yaml:"metrics"`
+	Monitor   MonitorConfig   `yaml:"monitor"`
+	Alarms    AlarmsConfig    `yaml:"alarms"`
+	Telemetry TelemetryConfig `yaml:"telemetry"`
+
+	// uplinkers the TUI connects to on startup, one tab each
+	Connections []ConnectionConfig `yaml:"connections"`
+
+	Sections SectionsConfig `yaml:"sections"`
+}
+
+type ClockConfig struct {
+	// largest offset between host and board time accepted after a jump
+	MaxOffset time.Duration `yaml:"max_offset"`
+
+	// how long the drift test keeps sampling, and how often
+	DriftDuration time.Duration `yaml:"drift_duration"`
+	DriftInterval time.Duration `yaml:"drift_interval"`
+
+	// largest drift accepted from any board, in parts per million
+	MaxDriftPPM float64 `yaml:"max_drift_ppm"`
+
+	// how far board timestamps may disagree with each other and with the host
+	TimestampTolerance time.Duration `yaml:"timestamp_tolerance"`
+}
+
+type AltimeterConfig struct {
+	// ground level pressure in Pa used until the altimeter is zeroed at the pad
+	GroundPressurePa float64 `yaml:"ground_pressure_pa"`
+}
+
+type GPSConfig struct {
+	// pad area the tracker has to report before flight, leave empty to skip the check
+	LaunchSite LaunchSiteBounds `yaml:"launch_site"`
+
+	// where GPX/KML waypoints of each fix are written
+	ExportDir string `yaml:"export_dir"`
+}
+
+type LaunchSiteBounds struct {
+	MinLat float64 `yaml:"min_lat"`
+	MaxLat float64 `yaml:"max_lat"`
+	MinLon float64 `yaml:"min_lon"`
+	MaxLon float64 `yaml:"max_lon"`
+}
+
+type IMUConfig struct {
+	// accelerometer magnitude at rest, in whatever units the IMU reports
+	Gravity          float64 `yaml:"gravity"`
+	GravityTolerance float64 `yaml:"gravity_tolerance"`
+
+	MaxGyroBias float64 `yaml:"max_gyro_bias"`
+	Samples     int     `yaml:"samples"`
+}
+
+type SDConfig struct {
+	// how long each board is left logging during the SD check
+	TestWindow time.Duration `yaml:"test_window"`
+
+	// how far the SD timestamp advance may stray from the wall clock, as a fraction
+	TimestampTolerance float64 `yaml:"timestamp_tolerance"`
+
+	// keyed by board name, e.g. "Radio" or "Digital V2"
+	Boards map[string]SDBoardConfig `yaml:"boards"`
+}
+
+type SDBoardConfig struct {
+	MinBytesPerSec float64 `yaml:"min_bytes_per_sec"`
+}
+
+// what every SD card has to be able to hold
+type MissionConfig struct {
+	PadWait time.Duration `yaml:"pad_wait"`
+	Flight  time.Duration `yaml:"flight"`
+
+	// flight logging rate as a multiple of the pad rate
+	FlightRateMultiplier float64 `yaml:"flight_rate_multiplier"`
+}
+
+// settings that differ between the nose cone and the body tube
+type SectionsConfig struct {
+	NoseCone SectionConfig `yaml:"nose_cone"`
+	BodyTube SectionConfig `yaml:"body_tube"`
+}
+
+type SectionConfig struct {
+	IMU   IMUOrientation `yaml:"imu"`
+	Shock ShockConfig    `yaml:"shock"`
+
+	// procedure file walked through in the TUI checklist mode
+	Checklist string `yaml:"checklist"`
+
+	// procedure file repeated by the pad hold monitor
+	Monitor string `yaml:"monitor"`
+}
+
+type ChecklistConfig struct {
+	// where signed checklists are saved, next to the session log by default
+	RecordDir string `yaml:"record_dir"`
+}
+
+type VersionsConfig struct {
+	// refuse to connect when the radio does not answer the version query,
+	// otherwise every V1 and V2 entry is shown as before
+	RequireHandshake bool `yaml:"require_handshake"`
+
+	// oldest firmware, as major.minor, that passes without a warning
+	MinFirmware MinFirmwareConfig `yaml:"min_firmware"`
+}
+
+type MinFirmwareConfig struct {
+	Radio   string `yaml:"radio"`
+	Analog  string `yaml:"analog"`
+	Digital string `yaml:"digital"`
+}
+
+type ServerConfig struct {
+	// where `ilaye serve` listens, only this machine by default
+	Addr string `yaml:"addr"`
+
+	// bearer token every API request has to carry, empty leaves the API open and
+	// is only allowed on a loopback address
+	Token string `yaml:"token"`
+}
+
+type BridgeConfig struct {
+	// where `ilaye bridge` shares the uplinker, only this machine by default
+	Addr string `yaml:"addr"`
+
+	// shared secret the TUI sends before any frame and the bridge checks, the bridge
+	// only listens beyond loopback with a token or an allow list
+	Token string `yaml:"token"`
+
+	// client addresses or CIDRs the bridge accepts, empty accepts any client with the token
+	Allow []string `yaml:"allow"`
+
+	// host:port bridges listed in the TUI port picker next to the local ports
+	Remotes []string `yaml:"remotes"`
+}
+
+type MonitorConfig struct {
+	// time between the start of one monitor cycle and the next
+	Interval time.Duration `yaml:"interval"`
+}
+
+type ConnectionConfig struct {
+	// shown on the tab, e.g. "Nose cone uplinker"
+	Name string `yaml:"name"`
+
+	// serial port or host:port of a bridge
+	Port string `yaml:"port"`
+}
+
+type AlarmsConfig struct {
+	// rules evaluated on every reply, a missing file leaves the alarms off
+	File string `yaml:"file"`
+
+	// every raised, acknowledged, normal and cleared alarm is appended here
+	LogFile string `yaml:"log_file"`
+}
+
+type TelemetryConfig struct {
+	// where every recording gets its own directory of per sensor files
+	RecordDir string `yaml:"record_dir"`
+
+	// csv, parquet and jsonl, each sensor is written in every format listed
+	Formats []string `yaml:"formats"`
+
+	// a sensor starts a new file once its file reaches this many bytes or is open this long, 0 never
+	RotateSize  int64         `yaml:"rotate_size"`
+	RotateEvery time.Duration `yaml:"rotate_every"`
+
+	// also record every reading polled over a connection, e.g. during checkout
+	RecordPolled bool `yaml:"record_polled"`
+
+	// list "Stream Telemetry" in the TUI, its packet format is ILAYE's proposal and no firmware streams it yet
+	ProposedStream bool `yaml:"proposed_stream"`
+}
+
+type MetricsConfig struct {
+	// where Prometheus scrapes /metrics, empty leaves the endpoint off
+	Addr string `yaml:"addr"`
+}
+
+// how the shock accelerometers are mounted relative to the IMU
+type ShockConfig struct {
+	Shock1 SensorMounting `yaml:"shock_1"`
+	Shock2 SensorMounting `yaml:"shock_2"`
+
+	// a sensor reading less than this at rest is taken as dead, in the IMU's units
+	DeadThreshold float64 `yaml:"dead_threshold"`
+
+	// largest difference between any two accelerometers, in the IMU's units
+	Tolerance float64 `yaml:"tolerance"`
+}
+
+type SensorMounting struct {
+	// multiplies the raw reading into the IMU's units
+	Scale float64 `yaml:"scale"`
+
+	// rotation from the sensor frame into the IMU frame, row major
+	Rotation [3][3]float64 `yaml:"rotation"`
+}
+
+// orientation of the IMU when the vehicle sits on the rail
+type IMUOrientation struct {
+	// direction in the IMU frame that points up, all zero skips the check
+	UpAxis       [3]float64 `yaml:"up_axis"`
+	ToleranceDeg float64    `yaml:"tolerance_deg"`
+}
+
+func Default() *Config {
+	return &Config{
+		Clock: ClockConfig{
+			MaxOffset:     500 * time.Millisecond,
+			DriftDuration: 5 * time.Minute,
+			DriftInterval: 30 * time.Second,
+			MaxDriftPPM:   100,
+
+			TimestampTolerance: 2 * time.Second,
+		},
+		Altimeter: AltimeterConfig{
+			GroundPressurePa: 101325,
+		},
+		GPS: GPSConfig{
+			ExportDir: "waypoints",
+		},
+		IMU: IMUConfig{
+			Gravity:          9.80665,
+			GravityTolerance: 0.5,
+			MaxGyroBias:      0.05,
+			Samples:          10,
+		},
+		SD: SDConfig{
+			TestWindow:         5 * time.Second,
+			TimestampTolerance: 0.5,
+			Boards:             map[string]SDBoardConfig{},
+		},
+		Mission: MissionConfig{
+			PadWait:              4 * time.Hour,
+			Flight:               30 * time.Minute,
+			FlightRateMultiplier: 1,
+		},
+		Checklist: ChecklistConfig{
+			RecordDir: ".",
+		},
+		Server: ServerConfig{
+			Addr: "127.0.0.1:8420",
+		},
+		Bridge: BridgeConfig{
+			Addr: "127.0.0.1:8421",
+		},
+		Monitor: MonitorConfig{
+			Interval: 5 * time.Minute,
+		},
+		Telemetry: TelemetryConfig{
+			RecordDir:   "telemetry",
+			Formats:     []string{"csv"},
+			RotateSize:  64 << 20,
+			RotateEvery: time.Hour,
+		},
+		Alarms: AlarmsConfig{
+			File:    "alarms.yaml",
+			LogFile: "alarms.log",
+		},
+		Sections: SectionsConfig{
+			NoseCone: SectionConfig{
+				IMU:       IMUOrientation{ToleranceDeg: 15},
+				Shock:     ShockConfig{DeadThreshold: 2, Tolerance: 2},
+				Checklist: "procedures/nose_cone_checklist.yaml",
+				Monitor:   "procedures/nose_cone_monitor.yaml",
+			},
+			BodyTube: SectionConfig{
+				IMU:       IMUOrientation{ToleranceDeg: 15},
+				Shock:     ShockConfig{DeadThreshold: 2, Tolerance: 2},
+				Checklist: "procedures/body_tube_checklist.yaml",
+				Monitor:   "procedures/body_tube_monitor.yaml",
+			},
+		},
+	}
+}
+
+// load the config file on top of the defaults, a missing file is not an error
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	contents, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(contents, cfg); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,77 @@
+// Code generated by cmd/protogen from protocol/protocol.yaml. DO NOT EDIT.
+
+package globals
+
+const (
+	// all possible command sequences
+	CMD_ENTER_NORMAL      = 0x00
+	CMD_ENTER_INSPECT     = 0x01
+	CMD_JUMP_CLK          = 0x0B
+	CMD_TEST_SERIAL_CONN  = 0x0C
+	CMD_ENTER_LAUNCH_MODE = 0x04
+	CMD_GET_VERSION       = 0x0D
+
+	// radio
+	CMD_GET_RADIO_SD_UPDATE = 0x20
+	CMD_CLEAR_RADIO_SD      = 0x2E
+
+	// analog v1
+	CMD_GET_ANALOG_V1_SD_UPDATE  = 0xA0
+	CMD_GET_ANALOG_V1_PT_READING = 0xA2
+	CMD_CLEAR_ANALOG_V1_SD       = 0xAE
+
+	// digital v1
+	CMD_GET_DIGITAL_V1_SD_UPDATE         = 0xB0
+	CMD_GET_DIGITAL_V1_ALTIMETER_READING = 0xB1
+	CMD_GET_DIGITAL_V1_SHOCK_1_READING   = 0xB3
+	CMD_GET_DIGITAL_V1_IMU_READING       = 0xB5
+	CMD_CLEAR_DIGITAL_V1_SD              = 0xBE
+
+	// analog v2
+	CMD_GET_ANALOG_V2_SD_UPDATE  = 0xC0
+	CMD_GET_ANALOG_V2_PT_READING = 0xC2
+	CMD_CLEAR_ANALOG_V2_SD       = 0xCE
+
+	// digital v2
+	CMD_GET_DIGITAL_V2_SD_UPDATE         = 0xD0
+	CMD_GET_DIGITAL_V2_ALTIMETER_READING = 0xD1
+	CMD_GET_DIGITAL_V2_GPS_READING       = 0xD2
+	CMD_GET_DIGITAL_V2_SHOCK_1_READING   = 0xD3
+	CMD_GET_DIGITAL_V2_SHOCK_2_READING   = 0xD4
+	CMD_GET_DIGITAL_V2_IMU_READING       = 0xD5
+	CMD_CLEAR_DIGITAL_V2_SD              = 0xDE
+)
+
+// version of the protocol this build speaks, the radio has to report the same
+const PROTOCOL_VERSION = 1
+
+// replies the radio sends in place of the expected struct
+const (
+	CMD_TIMEOUT                  = 0xFF
+	RADIO_COMMAND_NOT_RECOGNIZED = 0xFE
+	RADIO_COMMAND_INCORRECT      = 0xFD
+	RADIO_START_OR_END_WRONG     = 0xFC
+	CAN_RESPONSE_WRONG           = 0xFB
+	RADIO_COMMAND_ERROR          = 0xFA
+)
+
+// host side actions, these are never sent over the radio and only
+// identify checks that ILAYE runs on its own across several commands
+const (
+	HOST_CLOCK_DRIFT_TEST          = 0xE0
+	HOST_TIMESTAMP_CHECK           = 0xE1
+	HOST_SHOCK_CROSS_CHECK         = 0xE2
+	HOST_SD_CAPACITY_PLAN          = 0xE3
+	HOST_ZERO_DIGITAL_V1_ALTIMETER = 0xE4
+	HOST_ZERO_DIGITAL_V2_ALTIMETER = 0xE5
+)
+
+// types of the telemetry packets the boards stream in normal mode
+const (
+	TLM_ANALOG_PT         = 0x70
+	TLM_DIGITAL_ALTIMETER = 0x71
+	TLM_DIGITAL_IMU       = 0x72
+	TLM_DIGITAL_GPS       = 0x73
+	TLM_DIGITAL_SHOCK_1   = 0x74
+	TLM_DIGITAL_SHOCK_2   = 0x75
+)
@@ -7,7 +7,8 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
-func NewLogger(logFilePath string) (*zap.Logger, error) {
+// every line also goes to ring when it is not nil, so the TUI can show it next to the test that caused it
+func NewLogger(logFilePath string, ring *RingBuffer) (*zap.Logger, error) {
 	// only log errors to the file
 	warnFile, err := os.OpenFile(logFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
@@ -24,8 +25,13 @@ func NewLogger(logFilePath string) (*zap.Logger, error) {
 		zapcore.DebugLevel,
 	)
 
+	core := fileCore
+	if ring != nil {
+		core = zapcore.NewTee(fileCore, zapcore.NewCore(encoder, ring, zapcore.DebugLevel))
+	}
+
 	logger := zap.New(
-		fileCore,
+		core,
 		zap.AddCaller(),
 		zap.AddStacktrace(zapcore.WarnLevel),
 		zap.Development(),
@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// how many debug lines are kept in memory for the TUI
+const RING_BUFFER_SIZE = 2000
+
+type Entry struct {
+	Time    time.Time
+	Message string
+}
+
+// keeps the most recent log lines so the TUI can show what the serial layer did during a test
+type RingBuffer struct {
+	mu      sync.Mutex
+	entries []Entry
+	next    int
+	full    bool
+}
+
+func NewRingBuffer(size int) *RingBuffer {
+	return &RingBuffer{entries: make([]Entry, size)}
+}
+
+// satisfies zapcore.WriteSyncer, each write is one encoded log line
+func (r *RingBuffer) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries[r.next] = Entry{Time: time.Now(), Message: strings.TrimSuffix(string(p), "\n")}
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+	return len(p), nil
+}
+
+func (r *RingBuffer) Sync() error {
+	return nil
+}
+
+// lines logged within [start, end] in the order they were written, a zero end means up to now
+func (r *RingBuffer) Between(start time.Time, end time.Time) []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := r.next
+	first := 0
+	if r.full {
+		count = len(r.entries)
+		first = r.next
+	}
+
+	entries := []Entry{}
+	for i := range count {
+		entry := r.entries[(first+i)%len(r.entries)]
+		if entry.Time.Before(start) || (!end.IsZero() && entry.Time.After(end)) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
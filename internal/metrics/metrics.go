@@ -0,0 +1,122 @@
+/**
+Prometheus metrics of the radio link and the last sensor readings
+
+The serial layer counts resyncs, overflows and timeouts as they happen, and every
+connection handed out by the connector is observed so each command and its reply are
+timed and decoded. Nothing is exposed unless `metrics.addr` is set in the config.
+*/
+
+package metrics
+
+import (
+	"UCLA-Rocket-Project/ILAYE/internal/commander"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+)
+
+var (
+	CommandsSent = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ilaye_commands_sent_total",
+		Help: "Frames written to the uplinker, by command.",
+	}, []string{"command"})
+
+	ReadTimeouts = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "ilaye_read_timeouts_total",
+		Help: "Replies that did not arrive before the read timeout.",
+	})
+
+	Resyncs = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "ilaye_serial_resyncs_total",
+		Help: "Times the serial reader had to resync on the stop sequence.",
+	})
+
+	BufferOverflows = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "ilaye_serial_buffer_overflows_total",
+		Help: "Messages cut off because they never reached the stop sequence.",
+	})
+
+	UnmatchedFrames = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "ilaye_unmatched_frames_total",
+		Help: "Frames that were not the reply to the command waiting, e.g. late replies or telemetry.",
+	})
+
+	ErrorReplies = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ilaye_error_replies_total",
+		Help: "Error codes the radio replied with instead of the expected struct.",
+	}, []string{"command", "error"})
+
+	RoundTrip = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "ilaye_command_round_trip_seconds",
+		Help: "Time from writing a command to reading its reply.",
+		// the boards answer in tens of milliseconds and time out after 22s
+		Buckets: []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 25},
+	}, []string{"command"})
+
+	LastValue = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ilaye_last_reply_value",
+		Help: "Last decoded value of every numeric reply field, raw as the board sent it.",
+	}, []string{"command", "field"})
+
+	LastReply = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ilaye_last_reply_timestamp_seconds",
+		Help: "Unix time of the last reply to each command.",
+	}, []string{"command"})
+)
+
+// serve /metrics on its own listener so it works next to the TUI as well as `ilaye serve`
+func Serve(addr string, log *zap.Logger) {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", promhttp.Handler())
+
+	log.Info("Serving metrics", zap.String("addr", addr))
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		log.Error("Metrics endpoint stopped", zap.Error(err), zap.String("addr", addr))
+	}
+}
+
+func commandLabel(opcode byte) string {
+	if name, ok := commander.OpcodeName(opcode); ok {
+		return name
+	}
+	return fmt.Sprintf("0x%02X", opcode)
+}
+
+// times every command and records the fields of its reply, attached with commander.Observe
+type Observer struct{}
+
+func (Observer) Sent(opcode byte, at time.Time) {
+	CommandsSent.WithLabelValues(commandLabel(opcode)).Inc()
+}
+
+func (Observer) Replied(reply commander.Reply) {
+	// timeouts are counted by the serial layer, which knows a timeout from a dropped port
+	if reply.Err != nil {
+		return
+	}
+
+	command := commandLabel(reply.Opcode)
+	if !reply.SentAt.IsZero() {
+		RoundTrip.WithLabelValues(command).Observe(reply.ReceivedAt.Sub(reply.SentAt).Seconds())
+	}
+	LastReply.WithLabelValues(command).SetToCurrentTime()
+
+	if reply.Decoded == nil {
+		return
+	}
+	if name, ok := commander.ErrorCodeName(reply.Raw[0]); ok && reply.Decoded.Layout == name {
+		ErrorReplies.WithLabelValues(command, name).Inc()
+		return
+	}
+	for _, field := range reply.Decoded.Fields {
+		if value, err := strconv.ParseFloat(field.Value, 64); err == nil {
+			LastValue.WithLabelValues(command, field.Name).Set(value)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+/**
+Health monitor for long pad holds
+
+A monitor is a procedure file whose steps are repeated every interval while the
+vehicle waits on the pad. Every step runs on every cycle regardless of
+on_failure and goto, a failed step only raises an alert. Sample steps can list
+fields that have to keep growing between cycles, e.g. the SD file size.
+Prompts are not allowed since nobody is expected to be watching.
+*/
+
+package monitor
+
+import (
+	"UCLA-Rocket-Project/ILAYE/internal/checkout"
+	"UCLA-Rocket-Project/ILAYE/internal/procedure"
+	"fmt"
+	"io"
+)
+
+type Monitor struct {
+	Procedure *procedure.Procedure
+
+	// fields sampled by each step on the previous cycle, for the growing checks
+	previous map[int]map[string]float64
+}
+
+// load a monitor file and check it against the menus of the checkout
+func Load(path string, c checkout.Checkout) (*Monitor, error) {
+	proc, err := procedure.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	section, _ := proc.CheckoutSection()
+	if section != c.Section {
+		return nil, fmt.Errorf("%s is a %s monitor", path, section)
+	}
+	if err := proc.Validate(c); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for i, step := range proc.Steps {
+		if step.IsManual() {
+			return nil, fmt.Errorf("%s: step %d: prompts cannot run unattended", path, i+1)
+		}
+	}
+
+	return &Monitor{Procedure: proc, previous: map[int]map[string]float64{}}, nil
+}
+
+// run one step of the current cycle and report whether it is still healthy
+func (m *Monitor) RunCheck(c checkout.Checkout, log io.Writer, index int) bool {
+	step := m.Procedure.Steps[index]
+	if step.Sample == "" {
+		return procedure.RunStep(c, log, nil, step)
+	}
+
+	fields, ok := c.Sample(log, step.Sample)
+	if !ok {
+		fmt.Fprintf(log, "[Monitor]: Could not sample %s\n", step.Sample)
+		return false
+	}
+	healthy := procedure.CheckLimits(log, step.Sample, fields, step.Limits)
+
+	previous, seen := m.previous[index]
+	m.previous[index] = fields
+	for _, field := range step.Growing {
+		value, ok := fields[field]
+		if !ok {
+			fmt.Fprintf(log, "[Monitor]: %s has no field %s\n", step.Sample, field)
+			healthy = false
+			continue
+		}
+		if !seen {
+			continue
+		}
+		if value <= previous[field] {
+			fmt.Fprintf(log, "[Monitor]: %s %s is not growing, %g since the last cycle\n", step.Sample, field, value-previous[field])
+			healthy = false
+		}
+	}
+
+	return healthy
+}
@@ -0,0 +1,199 @@
+/**
+Scriptable checkout sequences
+
+A procedure is a YAML file of ordered steps, each step does exactly one of:
+  run: <test or command name as shown in the menus>
+  wait: <duration>
+  sample: <sensor name> with limits on its fields
+  prompt: <instruction the operator has to acknowledge>
+
+On failure a step aborts the procedure unless on_failure says "continue" or
+"goto <step id>". On success it moves to the next step unless on_success says
+"end" or "goto <step id>".
+*/
+
+package procedure
+
+import (
+	"UCLA-Rocket-Project/ILAYE/internal/checkout"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ON_FAILURE_ABORT    = "abort"
+	ON_FAILURE_CONTINUE = "continue"
+	ON_FAILURE_GOTO     = "goto "
+
+	ON_SUCCESS_CONTINUE = "continue"
+	ON_SUCCESS_END      = "end"
+	ON_SUCCESS_GOTO     = "goto "
+)
+
+// how many steps a procedure may execute in total, guards against goto loops
+const MAX_EXECUTED_STEPS = 1000
+
+type Procedure struct {
+	Name    string `yaml:"name"`
+	Section string `yaml:"section"`
+	Steps   []Step `yaml:"steps"`
+}
+
+type Step struct {
+	// optional, only needed as a goto target
+	ID   string `yaml:"id"`
+	Name string `yaml:"name"`
+
+	Run    string           `yaml:"run"`
+	Wait   time.Duration    `yaml:"wait"`
+	Sample string           `yaml:"sample"`
+	Limits map[string]Limit `yaml:"limits"`
+	Prompt string           `yaml:"prompt"`
+
+	// sampled fields that have to increase every time the step runs again, only checked by the monitor
+	Growing []string `yaml:"growing"`
+
+	OnFailure string `yaml:"on_failure"`
+	OnSuccess string `yaml:"on_success"`
+}
+
+// inclusive bounds on a sampled field, a nil bound is open
+type Limit struct {
+	Min *float64 `yaml:"min"`
+	Max *float64 `yaml:"max"`
+}
+
+func (l Limit) String() string {
+	lower, upper := "-inf", "+inf"
+	if l.Min != nil {
+		lower = fmt.Sprintf("%g", *l.Min)
+	}
+	if l.Max != nil {
+		upper = fmt.Sprintf("%g", *l.Max)
+	}
+	return fmt.Sprintf("[%s, %s]", lower, upper)
+}
+
+func (l Limit) contains(value float64) bool {
+	return (l.Min == nil || value >= *l.Min) && (l.Max == nil || value <= *l.Max)
+}
+
+// human readable summary of what the step does, used in logs and the checklist
+func (s Step) Describe() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	switch {
+	case s.Run != "":
+		return s.Run
+	case s.Wait != 0:
+		return fmt.Sprintf("Wait %s", s.Wait)
+	case s.Sample != "":
+		return fmt.Sprintf("Sample %s", s.Sample)
+	default:
+		return s.Prompt
+	}
+}
+
+func (s Step) IsManual() bool {
+	return s.Prompt != ""
+}
+
+func Load(path string) (*Procedure, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var procedure Procedure
+	if err := yaml.Unmarshal(contents, &procedure); err != nil {
+		return nil, err
+	}
+
+	if _, err := procedure.CheckoutSection(); err != nil {
+		return nil, err
+	}
+
+	return &procedure, nil
+}
+
+func (p *Procedure) CheckoutSection() (checkout.Section, error) {
+	return checkout.ParseSection(p.Section)
+}
+
+// check every step against the menus of the checkout before anything is sent to the vehicle
+func (p *Procedure) Validate(c checkout.Checkout) error {
+	ids := map[string]int{}
+	for i, step := range p.Steps {
+		if step.ID == "" {
+			continue
+		}
+		if _, ok := ids[step.ID]; ok {
+			return fmt.Errorf("step %d: duplicate id %q", i+1, step.ID)
+		}
+		ids[step.ID] = i
+	}
+
+	for i, step := range p.Steps {
+		actions := 0
+		for _, set := range []bool{step.Run != "", step.Wait != 0, step.Sample != "", step.Prompt != ""} {
+			if set {
+				actions++
+			}
+		}
+		if actions != 1 {
+			return fmt.Errorf("step %d: needs exactly one of run, wait, sample or prompt", i+1)
+		}
+
+		if step.Wait < 0 {
+			return fmt.Errorf("step %d: wait %s is negative", i+1, step.Wait)
+		}
+
+		if step.Run != "" {
+			_, isTest := c.FindTest(step.Run)
+			_, isCommand := c.FindCommand(step.Run)
+			if !isTest && !isCommand {
+				return fmt.Errorf("step %d: %q is not a %s test or command", i+1, step.Run, c.Section)
+			}
+		}
+
+		if step.Sample != "" && !checkout.IsSensor(step.Sample) {
+			return fmt.Errorf("step %d: unknown sensor %q, expected one of %s", i+1, step.Sample, strings.Join(checkout.SensorNames(), ", "))
+		}
+
+		if len(step.Growing) > 0 && step.Sample == "" {
+			return fmt.Errorf("step %d: growing only applies to sample steps", i+1)
+		}
+
+		if target, ok := strings.CutPrefix(step.OnFailure, ON_FAILURE_GOTO); ok {
+			if _, ok := ids[target]; !ok {
+				return fmt.Errorf("step %d: goto unknown step %q", i+1, target)
+			}
+		} else if step.OnFailure != "" && step.OnFailure != ON_FAILURE_ABORT && step.OnFailure != ON_FAILURE_CONTINUE {
+			return fmt.Errorf("step %d: on_failure must be abort, continue or goto <id>", i+1)
+		}
+
+		if target, ok := strings.CutPrefix(step.OnSuccess, ON_SUCCESS_GOTO); ok {
+			if _, ok := ids[target]; !ok {
+				return fmt.Errorf("step %d: goto unknown step %q", i+1, target)
+			}
+		} else if step.OnSuccess != "" && step.OnSuccess != ON_SUCCESS_CONTINUE && step.OnSuccess != ON_SUCCESS_END {
+			return fmt.Errorf("step %d: on_success must be continue, end or goto <id>", i+1)
+		}
+	}
+
+	return nil
+}
+
+func (p *Procedure) indexOf(id string) int {
+	for i, step := range p.Steps {
+		if step.ID == id {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,88 @@
+package procedure
+
+import (
+	"UCLA-Rocket-Project/ILAYE/internal/checkout"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	c := checkout.Checkout{Section: checkout.SECTION_BODY_TUBE}
+
+	tests := []struct {
+		name  string
+		steps []Step
+		err   string // empty when the procedure is valid
+	}{
+		{"every kind of step", []Step{
+			{Run: "Test uplinker serial connection"},
+			{Run: "Enter Inspect Mode"},
+			{Wait: time.Second},
+			{Sample: "Analog V1 PT", Limits: map[string]Limit{"Ch[0]": {}}, Growing: []string{"Timestamp"}},
+			{Prompt: "Open the fill valve"},
+		}, ""},
+		{"no action", []Step{{Name: "Nothing"}}, "needs exactly one of"},
+		{"two actions", []Step{{Run: "Enter Inspect Mode", Wait: time.Second}}, "needs exactly one of"},
+		{"negative wait", []Step{{Wait: -time.Second}}, "wait -1s is negative"},
+		{"unknown run", []Step{{Run: "Launch"}}, "is not a Body Tube test or command"},
+		{"nose cone test on the body tube", []Step{{Run: "Get Digital V2 GPS Reading"}}, "is not a Body Tube test or command"},
+		{"unknown sensor", []Step{{Sample: "Radio PT"}}, "unknown sensor"},
+		{"growing on a run", []Step{{Run: "Enter Inspect Mode", Growing: []string{"FileSize"}}}, "growing only applies to sample steps"},
+		{"duplicate id", []Step{{ID: "a", Wait: time.Second}, {ID: "a", Wait: time.Second}}, "duplicate id"},
+		{"goto on failure", []Step{{ID: "retry", Run: "Enter Inspect Mode", OnFailure: "goto retry"}}, ""},
+		{"goto on success", []Step{{Wait: time.Second, OnSuccess: "goto done"}, {ID: "done", Wait: time.Second}}, ""},
+		{"goto unknown step", []Step{{Wait: time.Second, OnFailure: "goto nowhere"}}, "goto unknown step"},
+		{"unknown on_failure", []Step{{Wait: time.Second, OnFailure: "retry"}}, "on_failure must be"},
+		{"unknown on_success", []Step{{Wait: time.Second, OnSuccess: "abort"}}, "on_success must be"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := &Procedure{Name: test.name, Section: "body_tube", Steps: test.steps}
+			err := p.Validate(c)
+			if test.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("error %v, want one containing %q", err, test.err)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	p := &Procedure{Steps: []Step{
+		{ID: "start", Wait: time.Second},
+		{Wait: time.Second, OnFailure: ON_FAILURE_CONTINUE},
+		{Wait: time.Second, OnFailure: "goto start", OnSuccess: "goto last"},
+		{Wait: time.Second, OnSuccess: ON_SUCCESS_END},
+		{ID: "last", Wait: time.Second, OnFailure: ON_FAILURE_ABORT},
+	}}
+
+	tests := []struct {
+		name    string
+		current int
+		passed  bool
+		next    int
+	}{
+		{"pass moves on", 0, true, 1},
+		{"failure aborts by default", 0, false, -1},
+		{"failure continues", 1, false, 2},
+		{"failure goes back", 2, false, 0},
+		{"success jumps ahead", 2, true, 4},
+		{"success ends", 3, true, 5},
+		{"failure of an end step aborts", 3, false, -1},
+		{"last step finishes", 4, true, 5},
+		{"explicit abort", 4, false, -1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if next := p.next(test.current, test.passed); next != test.next {
+				t.Fatalf("next(%d, %v) = %d, want %d", test.current, test.passed, next, test.next)
+			}
+		})
+	}
+}
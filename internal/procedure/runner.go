@@ -0,0 +1,146 @@
+package procedure
+
+import (
+	"UCLA-Rocket-Project/ILAYE/internal/checkout"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// whoever is at the pad, Prompt blocks until they acknowledge the instruction
+type Operator interface {
+	Prompt(message string) error
+}
+
+// execute a single step and report whether it passed
+func RunStep(c checkout.Checkout, log io.Writer, op Operator, step Step) bool {
+	switch {
+	case step.Run != "":
+		if test, ok := c.FindTest(step.Run); ok {
+			return c.RunTest(log, test.OpCode)
+		}
+		if command, ok := c.FindCommand(step.Run); ok {
+			return c.RunCommand(log, command.OpCode)
+		}
+		fmt.Fprintf(log, "[Procedure]: %q is not a test or command\n", step.Run)
+		return false
+
+	case step.Wait != 0:
+		fmt.Fprintf(log, "[Procedure]: Waiting %s\n", step.Wait)
+		time.Sleep(step.Wait)
+		return true
+
+	case step.Sample != "":
+		fields, ok := c.Sample(log, step.Sample)
+		if !ok {
+			fmt.Fprintf(log, "[Procedure]: Could not sample %s\n", step.Sample)
+			return false
+		}
+		return CheckLimits(log, step.Sample, fields, step.Limits)
+
+	case step.Prompt != "":
+		if err := op.Prompt(step.Prompt); err != nil {
+			fmt.Fprintf(log, "[Procedure]: Operator did not acknowledge, %s\n", err)
+			return false
+		}
+		return true
+	}
+
+	return false
+}
+
+// log every sampled field and whether each limit holds
+func CheckLimits(log io.Writer, sensor string, fields map[string]float64, limits map[string]Limit) bool {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	readings := make([]string, 0, len(names))
+	for _, name := range names {
+		readings = append(readings, fmt.Sprintf("%s=%g", name, fields[name]))
+	}
+	fmt.Fprintf(log, "[Procedure]: %s: %s\n", sensor, strings.Join(readings, " "))
+
+	success := true
+	for field, limit := range limits {
+		value, ok := fields[field]
+		if !ok {
+			fmt.Fprintf(log, "[Procedure]: %s has no field %s\n", sensor, field)
+			success = false
+			continue
+		}
+		if !limit.contains(value) {
+			fmt.Fprintf(log, "[Procedure]: %s %s = %g is outside %s\n", sensor, field, value, limit)
+			success = false
+		}
+	}
+
+	return success
+}
+
+// where to go after a step, -1 aborts and len(steps) finishes the procedure
+func (p *Procedure) next(current int, passed bool) int {
+	step := p.Steps[current]
+	if passed {
+		if target, ok := strings.CutPrefix(step.OnSuccess, ON_SUCCESS_GOTO); ok {
+			return p.indexOf(target)
+		}
+		if step.OnSuccess == ON_SUCCESS_END {
+			return len(p.Steps)
+		}
+		return current + 1
+	}
+
+	if target, ok := strings.CutPrefix(step.OnFailure, ON_FAILURE_GOTO); ok {
+		return p.indexOf(target)
+	}
+	if step.OnFailure == ON_FAILURE_CONTINUE {
+		return current + 1
+	}
+	return -1
+}
+
+// run every step in order, following on_failure, and report whether all executed steps passed
+func Run(p *Procedure, c checkout.Checkout, log io.Writer, op Operator) bool {
+	if err := p.Validate(c); err != nil {
+		fmt.Fprintf(log, "[Procedure]: Invalid procedure, %s\n", err)
+		return false
+	}
+
+	fmt.Fprintf(log, "[Procedure]: Starting %q on the %s, %d steps\n", p.Name, c.Section, len(p.Steps))
+
+	success := true
+	executed := 0
+	for i := 0; i >= 0 && i < len(p.Steps); {
+		if executed >= MAX_EXECUTED_STEPS {
+			fmt.Fprintf(log, "[Procedure]: Stopping after %d steps, check the goto targets for a loop\n", executed)
+			return false
+		}
+		executed++
+
+		step := p.Steps[i]
+		fmt.Fprintf(log, "[Procedure]: Step %d/%d: %s\n", i+1, len(p.Steps), step.Describe())
+
+		passed := RunStep(c, log, op, step)
+		if passed {
+			fmt.Fprintf(log, "[Procedure]: Step %d passed\n", i+1)
+		} else {
+			fmt.Fprintf(log, "[Procedure]: Step %d failed\n", i+1)
+			success = false
+		}
+
+		next := p.next(i, passed)
+		if next < 0 {
+			fmt.Fprintf(log, "[Procedure]: Aborting\n")
+			return false
+		}
+		i = next
+	}
+
+	fmt.Fprintf(log, "[Procedure]: Finished %q\n", p.Name)
+	return success
+}
@@ -0,0 +1,244 @@
+package rpSerial
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"go.bug.st/serial"
+	"go.uber.org/zap"
+)
+
+// how long a client has to send the token once it connects
+const BRIDGE_AUTH_TIMEOUT = 5 * time.Second
+
+// longest token line read before the client is turned away
+const BRIDGE_MAX_TOKEN_LENGTH = 256
+
+// a flaky adapter is retried with a growing pause, and given up on after this many errors in a row
+const BRIDGE_READ_BACKOFF = 10 * time.Millisecond
+const BRIDGE_MAX_READ_BACKOFF = time.Second
+const BRIDGE_MAX_READ_ERRORS = 50
+
+// shares a local serial port with one ILAYE at a time over TCP, bytes are passed through untouched
+type SerialBridge struct {
+	port   serial.Port
+	logger *zap.Logger
+
+	// sent by the client as the first line, empty accepts a client without one
+	token string
+
+	// client networks accepted, empty accepts any address
+	allow []*net.IPNet
+
+	mu       sync.Mutex
+	client   net.Conn
+	listener net.Listener
+	portErr  error
+}
+
+// allow lists client addresses or CIDRs, e.g. 10.0.0.5 or 10.0.0.0/24
+func NewSerialBridge(portName string, baudrate int, token string, allow []string, logger *zap.Logger) (*SerialBridge, error) {
+	networks := []*net.IPNet{}
+	for _, entry := range allow {
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("%q is neither an address nor a CIDR", entry)
+			}
+			network = &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)}
+		}
+		networks = append(networks, network)
+	}
+
+	port, err := serial.Open(portName, &serial.Mode{BaudRate: baudrate})
+	if err != nil {
+		return nil, err
+	}
+	port.ResetInputBuffer()
+	port.ResetOutputBuffer()
+
+	return &SerialBridge{port: port, logger: logger, token: token, allow: networks}, nil
+}
+
+// returns once the listener fails or the bridged port is gone
+func (b *SerialBridge) Serve(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+	b.logger.Info("Serial bridge listening", zap.String("addr", listener.Addr().String()))
+
+	b.mu.Lock()
+	b.listener = listener
+	b.mu.Unlock()
+
+	go b.forwardFromPort()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			b.mu.Lock()
+			portErr := b.portErr
+			b.mu.Unlock()
+			if portErr != nil {
+				return portErr
+			}
+			return err
+		}
+		go b.accept(conn)
+	}
+}
+
+// check the client before it may reach the port
+func (b *SerialBridge) accept(conn net.Conn) {
+	client := conn.RemoteAddr().String()
+	if !b.allowed(conn.RemoteAddr()) {
+		b.logger.Warn("Refusing bridge client, its address is not allowed", zap.String("client", client))
+		conn.Close()
+		return
+	}
+	if err := b.authenticate(conn); err != nil {
+		b.logger.Warn("Refusing bridge client", zap.String("client", client), zap.Error(err))
+		conn.Close()
+		return
+	}
+
+	// a second ILAYE would interleave its commands with the first one's, so it is turned away
+	b.mu.Lock()
+	busy := b.client != nil
+	if !busy {
+		b.client = conn
+	}
+	b.mu.Unlock()
+	if busy {
+		b.logger.Warn("Refusing bridge client, the port is already in use", zap.String("client", client))
+		conn.Close()
+		return
+	}
+
+	b.logger.Info("Bridge client connected", zap.String("client", client))
+	b.forwardToPort(conn)
+}
+
+func (b *SerialBridge) allowed(addr net.Addr) bool {
+	if len(b.allow) == 0 {
+		return true
+	}
+	tcp, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+	for _, network := range b.allow {
+		if network.Contains(tcp.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// the token line is read a byte at a time so no frame after it is consumed
+func (b *SerialBridge) authenticate(conn net.Conn) error {
+	if b.token == "" {
+		return nil
+	}
+
+	conn.SetReadDeadline(time.Now().Add(BRIDGE_AUTH_TIMEOUT))
+	defer conn.SetReadDeadline(time.Time{})
+
+	line := []byte{}
+	one := [1]byte{}
+	for {
+		if _, err := conn.Read(one[:]); err != nil {
+			return fmt.Errorf("no token: %w", err)
+		}
+		if one[0] == '\n' {
+			break
+		}
+		line = append(line, one[0])
+		if len(line) > BRIDGE_MAX_TOKEN_LENGTH {
+			return errors.New("token line too long")
+		}
+	}
+
+	if subtle.ConstantTimeCompare(line, []byte(b.token)) != 1 {
+		return errors.New("wrong token")
+	}
+	return nil
+}
+
+// replies that arrive with nobody connected are dropped, the same as on an unopened port
+func (b *SerialBridge) forwardFromPort() {
+	buf := [TEMP_BUF_SIZE]byte{}
+	failures := 0
+	backoff := BRIDGE_READ_BACKOFF
+	for {
+		n, err := b.port.Read(buf[:])
+		if err != nil {
+			failures++
+			if portGone(err) || failures >= BRIDGE_MAX_READ_ERRORS {
+				b.stop(fmt.Errorf("bridged port failed after %d read errors: %w", failures, err))
+				return
+			}
+			// only the first error of a run is logged so a flaky adapter cannot flood the log
+			if failures == 1 {
+				b.logger.Error("Error while reading from the bridged port, retrying", zap.Error(err))
+			}
+			time.Sleep(backoff)
+			backoff = min(backoff*2, BRIDGE_MAX_READ_BACKOFF)
+			continue
+		}
+		if failures > 0 {
+			b.logger.Info("Bridged port readable again", zap.Int("errors", failures))
+			failures = 0
+			backoff = BRIDGE_READ_BACKOFF
+		}
+
+		b.mu.Lock()
+		client := b.client
+		b.mu.Unlock()
+		if client == nil || n == 0 {
+			continue
+		}
+		if _, err := client.Write(buf[:n]); err != nil {
+			b.logger.Warn("Error while writing to the bridge client", zap.Error(err))
+			client.Close()
+		}
+	}
+}
+
+// the port is gone, drop the client and make Serve return
+func (b *SerialBridge) stop(err error) {
+	b.logger.Error("Stopping the serial bridge", zap.Error(err))
+
+	b.mu.Lock()
+	b.portErr = err
+	client := b.client
+	listener := b.listener
+	b.mu.Unlock()
+
+	if client != nil {
+		client.Close()
+	}
+	listener.Close()
+}
+
+func (b *SerialBridge) forwardToPort(conn net.Conn) {
+	defer func() {
+		b.mu.Lock()
+		b.client = nil
+		b.mu.Unlock()
+		conn.Close()
+		b.logger.Info("Bridge client disconnected", zap.String("client", conn.RemoteAddr().String()))
+	}()
+
+	if _, err := io.Copy(b.port, conn); err != nil && !portGone(err) {
+		b.logger.Warn("Error while forwarding to the bridged port", zap.Error(err))
+	}
+}
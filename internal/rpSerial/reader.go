@@ -0,0 +1,165 @@
+package rpSerial
+
+import (
+	"UCLA-Rocket-Project/ILAYE/internal/metrics"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// timeout on the boards is 22 seconds
+const READ_TIMEOUT = 25 * time.Second
+
+// unmatched frames kept for a consumer such as the telemetry view, the oldest is dropped when nobody reads
+const UNMATCHED_BUFFER_SIZE = 256
+
+// a reply that shows up this long after its command timed out is logged as late rather than unsolicited
+const LATE_REPLY_WINDOW = time.Minute
+
+type Frame struct {
+	Data []byte
+	At   time.Time
+}
+
+// a frame the reader could not pair with the command waiting for a reply
+type UnmatchedFrame struct {
+	Frame
+	Reason string
+}
+
+type pendingCommand struct {
+	opcode byte
+	any    bool // a read without a command takes the next frame, whatever it is
+	sentAt time.Time
+	reply  chan Frame
+}
+
+// start the single reader of the port, every frame goes through it
+func (r *RpSerial) startReader() {
+	r.readerOnce.Do(func() {
+		r.unmatched = make(chan UnmatchedFrame, UNMATCHED_BUFFER_SIZE)
+		r.closed = make(chan struct{})
+		go r.readFrames()
+	})
+}
+
+func (r *RpSerial) readFrames() {
+	defer close(r.closed)
+	for {
+		data, err := r.ReadSingleMessage()
+		if err != nil {
+			r.logger.Warn("Frame reader stopped", zap.Error(err))
+			return
+		}
+		r.route(Frame{Data: data, At: time.Now()})
+	}
+}
+
+// hand frame to the pending command when it fits its reply, anything else goes to the event log
+func (r *RpSerial) route(frame Frame) {
+	r.mu.Lock()
+	pending := r.pending
+	var reason string
+	switch {
+	// a reply wins over telemetry, a reply struct can start with a byte that is also a telemetry type
+	case pending != nil && !pending.any && r.matcher.reply(pending.opcode, frame.Data):
+		r.pending = nil
+		r.mu.Unlock()
+		pending.reply <- frame
+		return
+	case r.matcher.telemetry(frame.Data):
+		reason = "telemetry"
+	case pending != nil && pending.any:
+		// a read without a command takes whatever comes next apart from streamed telemetry
+		r.pending = nil
+		r.mu.Unlock()
+		pending.reply <- frame
+		return
+	case pending != nil:
+		reason = fmt.Sprintf("does not fit the reply to 0x%02X", pending.opcode)
+	case r.expired != nil && !r.expired.any && frame.At.Sub(r.expired.sentAt) < LATE_REPLY_WINDOW && r.matcher.reply(r.expired.opcode, frame.Data):
+		reason = fmt.Sprintf("late reply to 0x%02X", r.expired.opcode)
+		r.expired = nil
+	default:
+		reason = "unsolicited"
+	}
+	r.mu.Unlock()
+
+	// telemetry is expected in normal mode and too frequent for the event log
+	if reason != "telemetry" {
+		r.events.Warn("Unmatched frame", zap.String("reason", reason), zap.String("frame", fmt.Sprintf("% x", frame.Data)), zap.Time("at", frame.At))
+		metrics.UnmatchedFrames.Inc()
+	}
+
+	unmatched := UnmatchedFrame{Frame: frame, Reason: reason}
+	select {
+	case r.unmatched <- unmatched:
+	default:
+		// drop the oldest so the latest frames are the ones kept
+		select {
+		case <-r.unmatched:
+		default:
+		}
+		select {
+		case r.unmatched <- unmatched:
+		default:
+		}
+	}
+}
+
+// telemetry and every other frame nobody asked for
+func (r *RpSerial) Unmatched() <-chan UnmatchedFrame {
+	r.startReader()
+	return r.unmatched
+}
+
+// register the command before it is written so a quick reply is never missed
+func (r *RpSerial) expect(opcode byte, any bool) *pendingCommand {
+	r.startReader()
+
+	pending := &pendingCommand{opcode: opcode, any: any, sentAt: time.Now(), reply: make(chan Frame, 1)}
+	r.mu.Lock()
+	if r.pending != nil {
+		r.expired = r.pending
+	}
+	r.pending = pending
+	if !any {
+		r.lastSent = pending
+	}
+	r.mu.Unlock()
+	return pending
+}
+
+func (r *RpSerial) ReadSingleOrTimeout() ([]byte, error) {
+	return r.ReadSingleWithin(READ_TIMEOUT)
+}
+
+// the same as ReadSingleOrTimeout with a shorter wait, for queries older firmware may not answer
+func (r *RpSerial) ReadSingleWithin(timeout time.Duration) ([]byte, error) {
+	r.mu.Lock()
+	pending := r.lastSent
+	r.lastSent = nil
+	r.mu.Unlock()
+	if pending == nil {
+		// nothing was sent since the last read, wait for whatever the boards send next
+		pending = r.expect(0, true)
+	}
+
+	select {
+	case frame := <-pending.reply:
+		return frame.Data, nil
+	case <-r.closed:
+		return nil, ErrPortClosed
+	case <-time.After(timeout):
+		metrics.ReadTimeouts.Inc()
+		r.mu.Lock()
+		if r.pending == pending {
+			r.pending = nil
+			r.expired = pending
+		}
+		r.mu.Unlock()
+		return nil, errors.New("read timeout")
+	}
+}
@@ -0,0 +1,98 @@
+package rpSerial
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// acks to 0x01 echo the opcode, replies to 0x10 are at least 4 bytes and telemetry starts with 0xA0
+var testMatcher = Matcher{
+	Reply: func(opcode byte, frame []byte) bool {
+		if opcode == 0x01 {
+			return len(frame) == 1 && frame[0] == opcode
+		}
+		return len(frame) >= 4
+	},
+	Telemetry: func(frame []byte) bool {
+		return len(frame) > 0 && frame[0] == 0xA0
+	},
+}
+
+func TestRoute(t *testing.T) {
+	now := time.Now()
+	command := func(opcode byte) *pendingCommand {
+		return &pendingCommand{opcode: opcode, sentAt: now, reply: make(chan Frame, 1)}
+	}
+	read := &pendingCommand{any: true, sentAt: now, reply: make(chan Frame, 1)}
+
+	// an empty reason means the frame went to the pending command
+	tests := []struct {
+		name    string
+		pending *pendingCommand
+		expired *pendingCommand
+		frame   []byte
+		reason  string
+	}{
+		{"reply of the exact size", command(0x10), nil, []byte{1, 2, 3, 4}, ""},
+		{"reply with trailing bytes", command(0x10), nil, []byte{1, 2, 3, 4, 5, 6}, ""},
+		{"reply cut short", command(0x10), nil, []byte{1, 2}, "does not fit the reply to 0x10"},
+		{"ack", command(0x01), nil, []byte{0x01}, ""},
+		{"ack of another command", command(0x01), nil, []byte{0x02}, "does not fit the reply to 0x01"},
+		{"telemetry while waiting", command(0x01), nil, []byte{0xA0, 1}, "telemetry"},
+		{"reply that starts like telemetry", command(0x10), nil, []byte{0xA0, 1, 2, 3}, ""},
+		{"plain read skips telemetry", read, nil, []byte{0xA0, 1}, "telemetry"},
+		{"plain read takes anything else", read, nil, []byte{0x55}, ""},
+		{"late reply", nil, command(0x01), []byte{0x01}, "late reply to 0x01"},
+		{"too late to be a reply", nil, &pendingCommand{opcode: 0x01, sentAt: now.Add(-2 * LATE_REPLY_WINDOW)}, []byte{0x01}, "unsolicited"},
+		{"nothing pending", nil, nil, []byte{0x01}, "unsolicited"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := &RpSerial{
+				logger:    zap.NewNop(),
+				events:    zap.NewNop(),
+				matcher:   testMatcher,
+				unmatched: make(chan UnmatchedFrame, 1),
+				pending:   test.pending,
+				expired:   test.expired,
+			}
+			r.route(Frame{Data: test.frame, At: now})
+
+			if test.reason == "" {
+				select {
+				case <-test.pending.reply:
+				default:
+					t.Fatalf("frame was not handed to the pending command")
+				}
+				if r.pending != nil {
+					t.Fatalf("pending command was not cleared")
+				}
+				return
+			}
+
+			select {
+			case unmatched := <-r.unmatched:
+				if unmatched.Reason != test.reason {
+					t.Fatalf("unmatched because %q, want %q", unmatched.Reason, test.reason)
+				}
+			default:
+				t.Fatalf("frame was neither delivered nor reported as unmatched")
+			}
+			if r.pending != test.pending {
+				t.Fatalf("pending command changed on an unmatched frame")
+			}
+		})
+	}
+}
+
+func TestNilMatcher(t *testing.T) {
+	var m Matcher
+	if !m.reply(0x10, []byte{1}) {
+		t.Fatalf("a nil reply matcher should take any frame")
+	}
+	if m.telemetry([]byte{0xA0}) {
+		t.Fatalf("a nil telemetry matcher should never see telemetry")
+	}
+}
@@ -10,10 +10,14 @@ This wrapper should:
 package rpSerial
 
 import (
+	"UCLA-Rocket-Project/ILAYE/internal/metrics"
 	"bytes"
 	"errors"
+	"fmt"
+	"io"
+	"net"
 	"strings"
-	"time"
+	"sync"
 
 	"go.bug.st/serial"
 	"go.uber.org/zap"
@@ -24,35 +28,85 @@ const TEMP_BUF_SIZE = 256
 var startSequence = []byte{0x08, 0x09}
 var stopSequence = []byte{0x1F, '\n'}
 
+var ErrPortClosed = errors.New("serial port closed")
+
+// a closed port or a dropped bridge connection never recovers, so resyncing on it would spin forever
+func portGone(err error) bool {
+	return errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed)
+}
+
+// how the reader tells replies and telemetry apart, the protocol is not known at this layer
+// so it is handed in when the port is built, e.g. commander.MatchesResponse and commander.IsTelemetry
+type Matcher struct {
+	// whether frame can be the reply to opcode, nil takes any frame
+	Reply func(opcode byte, frame []byte) bool
+
+	// whether frame is streamed telemetry rather than a reply, nil never is
+	Telemetry func(frame []byte) bool
+}
+
+func (m Matcher) reply(opcode byte, frame []byte) bool {
+	return m.Reply == nil || m.Reply(opcode, frame)
+}
+
+func (m Matcher) telemetry(frame []byte) bool {
+	return m.Telemetry != nil && m.Telemetry(frame)
+}
+
 type RpSerial struct {
 	serial.Port
 
-	logger *zap.Logger
+	logger  *zap.Logger
+	matcher Matcher
+
+	// unmatched frames are written here, apart from the serial layer's own lines
+	events *zap.Logger
+
+	// a single reader goroutine parses every frame and hands replies to the pending command
+	readerOnce sync.Once
+	unmatched  chan UnmatchedFrame
+	closed     chan struct{}
+
+	mu      sync.Mutex
+	pending *pendingCommand
+	expired *pendingCommand // the last command that timed out, to recognise its late reply
+
+	// the command written since the last read, route never clears it so a reply that
+	// arrives before the read starts waiting is still found in its channel
+	lastSent *pendingCommand
 }
 
-func NewRPSerial(portName string, baudrate int, logger *zap.Logger) *RpSerial {
+// a port that fails to open is returned as an error, `ilaye serve` keeps running for its other clients
+func NewRPSerial(portName string, baudrate int, matcher Matcher, logger *zap.Logger, events *zap.Logger) (*RpSerial, error) {
 	mode := &serial.Mode{
 		BaudRate: baudrate,
 	}
 
 	port, err := serial.Open(portName, mode)
 	if err != nil {
-		logger.Fatal("Error opening serial port", zap.Error(err), zap.String("portName", portName))
+		logger.Error("Error opening serial port", zap.Error(err), zap.String("portName", portName))
+		return nil, err
 	}
 
 	return &RpSerial{
-		Port:   port,
-		logger: logger,
-	}
+		Port:    port,
+		logger:  logger,
+		matcher: matcher,
+		events:  events,
+	}, nil
 }
 
-func (r *RpSerial) Sync() {
+func (r *RpSerial) Sync() error {
 	r.logger.Warn("Resyncing serial port")
+	metrics.Resyncs.Inc()
 	twoBytes := [2]byte{0x0, 0x0}
 	oneByte := [1]byte{}
 
 	for !bytes.Equal(twoBytes[:], stopSequence[:]) {
 		_, err := r.Read(oneByte[:])
+		if portGone(err) {
+			return ErrPortClosed
+		}
 		if err != nil {
 			r.logger.Warn("Error while resyncing serial port", zap.Error(err))
 		}
@@ -61,23 +115,26 @@ func (r *RpSerial) Sync() {
 		twoBytes[0] = twoBytes[1]
 		twoBytes[1] = oneByte[0]
 	}
+	return nil
 }
 
 // read a single message in the buffer, continue until you reach the end characters \r\n
-func (r *RpSerial) ReadSingleMessage() []byte {
+func (r *RpSerial) ReadSingleMessage() ([]byte, error) {
 	tempBufIdx := 0
 	tempBuf := [TEMP_BUF_SIZE]byte{}
 
 	// clear the serial terminal of anything that does not have to do with the actual data
-	r.readTillStartSequence()
+	if err := r.readTillStartSequence(); err != nil {
+		return nil, err
+	}
 
 	for {
 		_, err := r.Read(tempBuf[tempBufIdx : tempBufIdx+1])
-		r.logger.Info("Got some byte at actual read ", zap.Int("receivedByte", int(tempBuf[tempBufIdx])))
-
 		if err != nil {
 			r.logger.Error("Error while trying to read new sequence", zap.Error(err))
-			r.Sync()
+			if portGone(err) || r.Sync() != nil {
+				return nil, ErrPortClosed
+			}
 			tempBufIdx = 0 // Reset on error/sync
 			continue
 		}
@@ -91,14 +148,20 @@ func (r *RpSerial) ReadSingleMessage() []byte {
 		if tempBufIdx >= TEMP_BUF_SIZE {
 			tempBuf[TEMP_BUF_SIZE-1] = 0
 			r.logger.Warn("Buffer overflow, forcefully terminating", zap.ByteString("currentContents", tempBuf[:]))
-			return tempBuf[:]
+			metrics.BufferOverflows.Inc()
+			return tempBuf[:], nil
 		}
 	}
 
-	return tempBuf[:tempBufIdx-2]
+	// one line per frame, a line per byte would push everything else out of the TUI's debug logs
+	r.logger.Debug("Read frame", zap.Int("bytes", tempBufIdx-2), zap.String("frame", fmt.Sprintf("% x", tempBuf[:tempBufIdx-2])))
+	return tempBuf[:tempBufIdx-2], nil
 }
 
 func (r *RpSerial) WriteSingleMessage(message []byte, size int) {
+	if size > 0 {
+		r.expect(message[0], false)
+	}
 	n, err := r.Write(message[:size])
 
 	if err != nil {
@@ -151,39 +214,29 @@ func ListPorts() ([]string, error) {
 	return append(usbPorts, otherPorts...), nil
 }
 
-func (r *RpSerial) readTillStartSequence() {
+func (r *RpSerial) readTillStartSequence() error {
 	tempBuf := [2]byte{0x00, 0x00}
 	singleBuf := [1]byte{0x00}
+	read := 0
 
 	for {
 		_, err := r.Read(singleBuf[:])
-
-		r.logger.Info("Got some bytes while clearing for right start sequence ", zap.Int("receivedByte", int(singleBuf[0])))
 		if err != nil {
 			r.logger.Error("Error while trying to read new sequence", zap.Error(err))
-			r.Sync()
+			if portGone(err) || r.Sync() != nil {
+				return ErrPortClosed
+			}
 			continue
 		}
+		read++
 
 		tempBuf[0], tempBuf[1] = tempBuf[1], singleBuf[0]
 		if tempBuf[0] == startSequence[0] && tempBuf[1] == startSequence[1] {
-			return
+			// anything before the start sequence, e.g. console output of the uplinker
+			if read > len(startSequence) {
+				r.logger.Debug("Skipped bytes before start sequence", zap.Int("bytes", read-len(startSequence)))
+			}
+			return nil
 		}
 	}
 }
-
-func (r *RpSerial) ReadSingleOrTimeout() ([]byte, error) {
-	resultCh := make(chan []byte, 1)
-
-	go func() {
-		resultCh <- r.ReadSingleMessage()
-	}()
-
-	select {
-	case res := <-resultCh:
-		return res, nil
-	// timeout on the boards is 22 seconds
-	case <-time.After(25 * time.Second):
-		return nil, errors.New("read timeout")
-	}
-}
@@ -0,0 +1,140 @@
+package rpSerial
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.bug.st/serial"
+	"go.uber.org/zap"
+)
+
+const TCP_DIAL_TIMEOUT = 5 * time.Second
+
+// how long ResetInputBuffer keeps reading once nothing is waiting
+const TCP_DRAIN_WINDOW = 50 * time.Millisecond
+
+// a port name is a bridge address when it is host:port with a numeric port,
+// device paths and COM ports never look like that
+func IsNetworkAddress(portName string) bool {
+	if strings.HasPrefix(portName, "/") {
+		return false
+	}
+	host, port, err := net.SplitHostPort(portName)
+	if err != nil || host == "" {
+		return false
+	}
+	_, err = strconv.Atoi(port)
+	return err == nil
+}
+
+// whether addr only listens on this machine, an empty host listens on every interface
+func IsLoopbackAddress(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil || host == "" {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// serial.Port over a TCP connection to `ilaye bridge` or a simulator, the bytes are passed
+// through untouched so framing, resyncs and timeouts behave exactly as on a local port
+type tcpPort struct {
+	net.Conn
+	readTimeout time.Duration
+}
+
+// token is sent as the first line when the bridge asks for one, empty for a bridge or simulator without
+func NewTCPSerial(addr string, token string, matcher Matcher, logger *zap.Logger, events *zap.Logger) (*RpSerial, error) {
+	conn, err := net.DialTimeout("tcp", addr, TCP_DIAL_TIMEOUT)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		if _, err := conn.Write([]byte(token + "\n")); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	logger.Info("Connected to serial bridge", zap.String("addr", addr))
+
+	return &RpSerial{
+		Port:    &tcpPort{Conn: conn},
+		logger:  logger,
+		matcher: matcher,
+		events:  events,
+	}, nil
+}
+
+func (p *tcpPort) Read(b []byte) (int, error) {
+	if p.readTimeout > 0 {
+		p.SetReadDeadline(time.Now().Add(p.readTimeout))
+	} else {
+		p.SetReadDeadline(time.Time{})
+	}
+
+	n, err := p.Conn.Read(b)
+	// a serial read timeout is not an error, it just returns nothing
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return n, nil
+	}
+	return n, err
+}
+
+// the baud rate and line settings belong to the bridge
+func (p *tcpPort) SetMode(mode *serial.Mode) error {
+	return nil
+}
+
+func (p *tcpPort) Drain() error {
+	return nil
+}
+
+// throw away whatever already arrived from the bridge
+func (p *tcpPort) ResetInputBuffer() error {
+	buf := [TEMP_BUF_SIZE]byte{}
+	for {
+		p.SetReadDeadline(time.Now().Add(TCP_DRAIN_WINDOW))
+		n, err := p.Conn.Read(buf[:])
+		if n == 0 || err != nil {
+			p.SetReadDeadline(time.Time{})
+			var netErr net.Error
+			if err == nil || errors.As(err, &netErr) && netErr.Timeout() {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+func (p *tcpPort) ResetOutputBuffer() error {
+	return nil
+}
+
+func (p *tcpPort) SetDTR(dtr bool) error {
+	return nil
+}
+
+func (p *tcpPort) SetRTS(rts bool) error {
+	return nil
+}
+
+func (p *tcpPort) GetModemStatusBits() (*serial.ModemStatusBits, error) {
+	return &serial.ModemStatusBits{}, nil
+}
+
+func (p *tcpPort) SetReadTimeout(t time.Duration) error {
+	p.readTimeout = t
+	return nil
+}
+
+func (p *tcpPort) Break(d time.Duration) error {
+	return nil
+}
@@ -0,0 +1,137 @@
+/**
+Offline decoding of the logs the boards write to their SD card
+
+A log is read as one reading struct written over and over, e.g. IMUData,
+packed little endian with no framing, the same layout the boards reply with
+and that protocol/protocol.yaml declares. The board revision picks which
+structs it can hold. Each record is decoded into the same fields the checks
+use and handed to a recorder, so post-flight data comes out in the same files
+as a telemetry recording.
+*/
+
+package sdlog
+
+import (
+	"UCLA-Rocket-Project/ILAYE/internal/commander"
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// where decoded records are written, e.g. a telemetry.SessionRecorder
+type Recorder interface {
+	RecordDecoded(sensor string, sequence uint64, decoded *commander.Decoded) error
+}
+
+type FieldStats struct {
+	Name  string  `json:"name"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Mean  float64 `json:"mean"`
+	First float64 `json:"first"`
+	Last  float64 `json:"last"`
+
+	sum   float64
+	count int
+}
+
+type Summary struct {
+	Board    string `json:"board"`
+	Revision uint8  `json:"revision"`
+	Record   string `json:"record"`
+
+	Bytes   int `json:"bytes"`
+	Records int `json:"records"`
+
+	// records that are all 0x00 or 0xFF, usually the unused end of the card
+	Erased int `json:"erased_records"`
+
+	// bytes after the last whole record, e.g. a write cut short by power loss
+	Trailing int `json:"trailing_bytes"`
+
+	Fields []FieldStats `json:"fields"`
+}
+
+// the struct a log of board revision holds, record may be empty when the board logs only one
+func Layout(board string, revision uint8, record string) (string, error) {
+	layouts := commander.SDRecordLayouts(board, revision)
+	if len(layouts) == 0 {
+		return "", fmt.Errorf("%s %d has no reading structs in the protocol", board, revision)
+	}
+	if record == "" {
+		if len(layouts) > 1 {
+			return "", fmt.Errorf("%s %d logs several structs, pick one of %s", board, revision, strings.Join(layouts, ", "))
+		}
+		return layouts[0], nil
+	}
+	for _, layout := range layouts {
+		if strings.EqualFold(layout, record) {
+			return layout, nil
+		}
+	}
+	return "", fmt.Errorf("%s %d does not log %s, expected one of %s", board, revision, record, strings.Join(layouts, ", "))
+}
+
+// decode every record of layout in raw into recorder, which may be nil to only summarise
+func Decode(raw []byte, board string, revision uint8, layout string, recorder Recorder) (*Summary, error) {
+	size, err := commander.SDRecordSize(layout)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &Summary{Board: board, Revision: revision, Record: layout, Bytes: len(raw), Trailing: len(raw) % size}
+	sensor := fmt.Sprintf("%s_v%d_%s", board, revision, layout)
+	fields := map[string]int{}
+	for offset := 0; offset+size <= len(raw); offset += size {
+		record := raw[offset : offset+size]
+		if erased(record) {
+			summary.Erased++
+			continue
+		}
+
+		decoded, err := commander.DecodeSDRecord(layout, record)
+		if err != nil {
+			return summary, err
+		}
+		summary.Records++
+		summary.add(fields, decoded)
+
+		if recorder != nil {
+			if err := recorder.RecordDecoded(sensor, uint64(offset/size), decoded); err != nil {
+				return summary, err
+			}
+		}
+	}
+
+	for i := range summary.Fields {
+		summary.Fields[i].Mean = summary.Fields[i].sum / float64(max(summary.Fields[i].count, 1))
+	}
+	return summary, nil
+}
+
+func erased(record []byte) bool {
+	return len(bytes.Trim(record, "\x00")) == 0 || len(bytes.Trim(record, "\xff")) == 0
+}
+
+func (s *Summary) add(fields map[string]int, decoded *commander.Decoded) {
+	for _, field := range decoded.Fields {
+		value, err := strconv.ParseFloat(field.Value, 64)
+		if err != nil || math.IsNaN(value) {
+			continue
+		}
+		i, ok := fields[field.Name]
+		if !ok {
+			i = len(s.Fields)
+			fields[field.Name] = i
+			s.Fields = append(s.Fields, FieldStats{Name: field.Name, Min: value, Max: value, First: value})
+		}
+		f := &s.Fields[i]
+		f.Min = min(f.Min, value)
+		f.Max = max(f.Max, value)
+		f.Last = value
+		f.sum += value
+		f.count++
+	}
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// events a slow client can fall behind by before it starts missing lines
+const LOG_STREAM_BUFFER = 256
+
+const LOG_STREAM_WRITE_TIMEOUT = 5 * time.Second
+
+// a log line of a result, or a change of its status when Line is empty
+type LogEvent struct {
+	Run    int       `json:"run"`
+	Result int       `json:"result"`
+	Name   string    `json:"name"`
+	Time   time.Time `json:"time"`
+	Line   string    `json:"line,omitempty"`
+	Status string    `json:"status,omitempty"`
+}
+
+type logHub struct {
+	mu          sync.Mutex
+	subscribers map[chan LogEvent]struct{}
+}
+
+func newLogHub() *logHub {
+	return &logHub{subscribers: make(map[chan LogEvent]struct{})}
+}
+
+func (h *logHub) subscribe() chan LogEvent {
+	ch := make(chan LogEvent, LOG_STREAM_BUFFER)
+	h.mu.Lock()
+	h.subscribers[ch] = struct{}{}
+	h.mu.Unlock()
+	return ch
+}
+
+func (h *logHub) unsubscribe(ch chan LogEvent) {
+	h.mu.Lock()
+	delete(h.subscribers, ch)
+	h.mu.Unlock()
+}
+
+// never blocks the run, a client that is not keeping up misses events
+// and can catch up from GET /api/runs/{id}
+func (h *logHub) publish(event LogEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+var upgrader = websocket.Upgrader{}
+
+func (s *Server) handleLogs(w http.ResponseWriter, r *http.Request) {
+	ws, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader already answered with the error
+		return
+	}
+	defer ws.Close()
+
+	events := s.logs.subscribe()
+	defer s.logs.unsubscribe(events)
+
+	// the stream is one way, reading only notices when the client goes away
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := ws.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case event := <-events:
+			ws.SetWriteDeadline(time.Now().Add(LOG_STREAM_WRITE_TIMEOUT))
+			if err := ws.WriteJSON(event); err != nil {
+				s.log.Warn("Dropping log stream client", zap.Error(err))
+				return
+			}
+		}
+	}
+}
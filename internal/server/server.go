@@ -0,0 +1,463 @@
+/**
+Local HTTP/JSON API for driving a checkout from another machine
+
+`ilaye serve` owns the serial connection and exposes the same tests and commands as
+the TUI, so mission control can run the checkout over the LAN:
+
+	GET  /api/ports                      serial ports on the pad box
+	POST /api/connect                    {"port": "..."}, connects and runs the version handshake
+	GET  /api/status                     connection, board versions and whether a run is going
+	GET  /api/sections/{section}/tests   tests of nose_cone or body_tube, as named in the menus
+	GET  /api/sections/{section}/commands
+	POST /api/runs                       {"section": "...", "kind": "tests", "names": [...]}
+	GET  /api/runs                       every run since the server started
+	GET  /api/runs/{id}                  results and logs of one run
+	GET  /api/logs                       WebSocket stream of log lines and result changes
+
+Only one run uses the connection at a time, a second run is refused until the first is done.
+*/
+
+package server
+
+import (
+	"UCLA-Rocket-Project/ILAYE/internal/checkout"
+	"UCLA-Rocket-Project/ILAYE/internal/commander"
+	"UCLA-Rocket-Project/ILAYE/internal/config"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type PortLister func() ([]string, error)
+type PortConnector func(string) (commander.SerialReaderWriter, error)
+
+// a client gets this long to send its headers, and an idle keep-alive connection is closed after the other
+const SERVER_READ_HEADER_TIMEOUT = 10 * time.Second
+const SERVER_IDLE_TIMEOUT = 2 * time.Minute
+
+// the one route a browser opens as a WebSocket
+const LOG_STREAM_PATH = "/api/logs"
+
+const (
+	RUN_TESTS    = "tests"
+	RUN_COMMANDS = "commands"
+)
+
+const (
+	STATUS_PENDING = "pending"
+	STATUS_RUNNING = "running"
+	STATUS_PASS    = "pass"
+	STATUS_FAIL    = "fail"
+)
+
+type LogLine struct {
+	Time time.Time `json:"time"`
+	Line string    `json:"line"`
+}
+
+type Result struct {
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	StartTime time.Time `json:"start_time,omitzero"`
+	EndTime   time.Time `json:"end_time,omitzero"`
+	Logs      []LogLine `json:"logs"`
+}
+
+type Run struct {
+	ID        int       `json:"id"`
+	Kind      string    `json:"kind"`
+	Section   string    `json:"section"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time,omitzero"`
+	Results   []Result  `json:"results"`
+}
+
+type Server struct {
+	config    *config.Config
+	log       *zap.Logger
+	listPorts PortLister
+	connect   PortConnector
+	logs      *logHub
+
+	// everything below is shared between the handlers and the run goroutine
+	mu            sync.Mutex
+	conn          commander.SerialReaderWriter
+	port          string
+	versions      *commander.BoardVersions
+	handshakeLogs []string
+	runs          []*Run
+	running       bool
+}
+
+func New(cfg *config.Config, log *zap.Logger, listPorts PortLister, connect PortConnector) *Server {
+	return &Server{
+		config:    cfg,
+		log:       log,
+		listPorts: listPorts,
+		connect:   connect,
+		logs:      newLogHub(),
+	}
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/ports", s.handlePorts)
+	mux.HandleFunc("POST /api/connect", s.handleConnect)
+	mux.HandleFunc("GET /api/status", s.handleStatus)
+	mux.HandleFunc("GET /api/sections/{section}/tests", s.handleEntries)
+	mux.HandleFunc("GET /api/sections/{section}/commands", s.handleEntries)
+	mux.HandleFunc("POST /api/runs", s.handleStartRun)
+	mux.HandleFunc("GET /api/runs", s.handleRuns)
+	mux.HandleFunc("GET /api/runs/{id}", s.handleRun)
+	mux.HandleFunc("GET "+LOG_STREAM_PATH, s.handleLogs)
+	return s.authorize(mux)
+}
+
+func (s *Server) ListenAndServe(addr string) error {
+	s.log.Info("Serving the ILAYE API", zap.String("addr", addr))
+	// no write timeout, the log stream stays open for as long as the client watches
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: SERVER_READ_HEADER_TIMEOUT,
+		IdleTimeout:       SERVER_IDLE_TIMEOUT,
+	}
+	return server.ListenAndServe()
+}
+
+// release the open connection, which also finishes its recordings
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if closer, ok := s.conn.(io.Closer); ok {
+		s.conn = nil
+		return closer.Close()
+	}
+	return nil
+}
+
+// browsers cannot set headers on a WebSocket, so the log stream also accepts the token as ?token=
+func (s *Server) authorize(next http.Handler) http.Handler {
+	token := s.config.Server.Token
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		header := r.Header.Get("Authorization")
+		sent, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok && header == "" && r.URL.Path == LOG_STREAM_PATH {
+			sent, ok = r.URL.Query().Get("token"), true
+		}
+		if !ok {
+			sent = ""
+		}
+		if subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+			writeError(w, http.StatusUnauthorized, "missing or wrong API token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, format string, args ...any) {
+	writeJSON(w, status, map[string]string{"error": fmt.Sprintf(format, args...)})
+}
+
+func (s *Server) handlePorts(w http.ResponseWriter, r *http.Request) {
+	ports, err := s.listPorts()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "could not list ports: %v", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, ports)
+}
+
+type status struct {
+	Connected     bool                     `json:"connected"`
+	Port          string                   `json:"port,omitempty"`
+	Versions      *commander.BoardVersions `json:"versions,omitempty"`
+	HandshakeLogs []string                 `json:"handshake_logs,omitempty"`
+	Running       bool                     `json:"running"`
+}
+
+// callers hold s.mu
+func (s *Server) status() status {
+	return status{
+		Connected:     s.conn != nil,
+		Port:          s.port,
+		Versions:      s.versions,
+		HandshakeLogs: s.handshakeLogs,
+		Running:       s.running,
+	}
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, s.status())
+}
+
+func (s *Server) handleConnect(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Port string `json:"port"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Port == "" {
+		writeError(w, http.StatusBadRequest, `expected {"port": "<serial port>"}`)
+		return
+	}
+
+	// the handshake can take a read timeout, so the lock is not held through it
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		writeError(w, http.StatusConflict, "the connection is busy")
+		return
+	}
+	s.running = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.running = false
+		s.mu.Unlock()
+	}()
+
+	// the old port is released first in case the same one is being reopened
+	s.mu.Lock()
+	if closer, ok := s.conn.(io.Closer); ok {
+		closer.Close()
+	}
+	s.conn, s.port, s.versions, s.handshakeLogs = nil, "", nil, nil
+	s.mu.Unlock()
+
+	conn, err := s.connect(request.Port)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, "could not connect to %s: %v", request.Port, err)
+		return
+	}
+
+	var log strings.Builder
+	versions, ok := checkout.Handshake(conn, &log, s.config)
+	logs := strings.Split(strings.TrimSuffix(log.String(), "\n"), "\n")
+	if !ok {
+		if closer, ok := conn.(io.Closer); ok {
+			closer.Close()
+		}
+		writeJSON(w, http.StatusPreconditionFailed, map[string]any{"error": logs[len(logs)-1], "handshake_logs": logs})
+		return
+	}
+
+	s.mu.Lock()
+	s.conn, s.port, s.versions, s.handshakeLogs = conn, request.Port, versions, logs
+	current := s.status()
+	s.mu.Unlock()
+
+	s.log.Info("API connected to port", zap.String("port", request.Port))
+	current.Running = false
+	writeJSON(w, http.StatusOK, current)
+}
+
+type entry struct {
+	Group  string `json:"group"`
+	Name   string `json:"name"`
+	OpCode string `json:"opcode"`
+}
+
+// the selectable entries of a menu with the header they sit under, without select all
+func entries(menu []checkout.CommandAndDesc) []entry {
+	list := []entry{}
+	group := ""
+	for i, item := range menu {
+		if i == 0 {
+			continue
+		}
+		if item.OpCode == checkout.FILLER_WHITESPACE {
+			group = strings.Trim(item.CommandName, "- ")
+			continue
+		}
+		list = append(list, entry{Group: group, Name: item.CommandName, OpCode: fmt.Sprintf("0x%02X", item.OpCode)})
+	}
+	return list
+}
+
+func (s *Server) handleEntries(w http.ResponseWriter, r *http.Request) {
+	section, err := checkout.ParseSection(r.PathValue("section"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "%v", err)
+		return
+	}
+
+	s.mu.Lock()
+	c := checkout.Checkout{Conn: s.conn, Config: s.config, Section: section, Versions: s.versions}
+	s.mu.Unlock()
+
+	if strings.HasSuffix(r.URL.Path, "/commands") {
+		writeJSON(w, http.StatusOK, entries(c.Commands()))
+		return
+	}
+	writeJSON(w, http.StatusOK, entries(c.Tests()))
+}
+
+func (s *Server) handleStartRun(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Section string   `json:"section"`
+		Kind    string   `json:"kind"`
+		Names   []string `json:"names"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid run request: %v", err)
+		return
+	}
+	section, err := checkout.ParseSection(request.Section)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+	if request.Kind != RUN_TESTS && request.Kind != RUN_COMMANDS {
+		writeError(w, http.StatusBadRequest, "unknown kind %q, expected %s or %s", request.Kind, RUN_TESTS, RUN_COMMANDS)
+		return
+	}
+	if len(request.Names) == 0 {
+		writeError(w, http.StatusBadRequest, "no %s named", request.Kind)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		writeError(w, http.StatusConflict, "not connected, POST /api/connect first")
+		return
+	}
+	if s.running {
+		writeError(w, http.StatusConflict, "the connection is busy")
+		return
+	}
+
+	// every name is checked before anything is sent, same as a procedure
+	c := checkout.Checkout{Conn: s.conn, Config: s.config, Section: section, Versions: s.versions}
+	opCodes := []byte{}
+	for _, name := range request.Names {
+		find := c.FindTest
+		if request.Kind == RUN_COMMANDS {
+			find = c.FindCommand
+		}
+		item, ok := find(name)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "no %s entry named %q on the %s", request.Kind, name, section)
+			return
+		}
+		opCodes = append(opCodes, item.OpCode)
+	}
+
+	run := &Run{
+		ID:        len(s.runs) + 1,
+		Kind:      request.Kind,
+		Section:   request.Section,
+		StartTime: time.Now(),
+	}
+	for _, name := range request.Names {
+		run.Results = append(run.Results, Result{Name: name, Status: STATUS_PENDING, Logs: []LogLine{}})
+	}
+	s.runs = append(s.runs, run)
+	s.running = true
+
+	s.log.Info("API run started", zap.Int("run", run.ID), zap.String("kind", run.Kind), zap.Strings("names", request.Names))
+	go s.execute(c, run, opCodes)
+
+	writeJSON(w, http.StatusAccepted, run)
+}
+
+func (s *Server) execute(c checkout.Checkout, run *Run, opCodes []byte) {
+	for i, opCode := range opCodes {
+		s.setStatus(run, i, STATUS_RUNNING)
+
+		w := resultWriter{s: s, run: run, index: i}
+		var success bool
+		if run.Kind == RUN_TESTS {
+			success = c.RunTest(w, opCode)
+		} else {
+			success = c.RunCommand(w, opCode)
+		}
+
+		if success {
+			s.setStatus(run, i, STATUS_PASS)
+		} else {
+			s.setStatus(run, i, STATUS_FAIL)
+		}
+	}
+
+	s.mu.Lock()
+	run.EndTime = time.Now()
+	s.running = false
+	s.mu.Unlock()
+	s.log.Info("API run finished", zap.Int("run", run.ID))
+}
+
+func (s *Server) setStatus(run *Run, index int, status string) {
+	s.mu.Lock()
+	result := &run.Results[index]
+	result.Status = status
+	switch status {
+	case STATUS_RUNNING:
+		result.StartTime = time.Now()
+	case STATUS_PASS, STATUS_FAIL:
+		result.EndTime = time.Now()
+	}
+	event := LogEvent{Run: run.ID, Result: index, Name: result.Name, Time: time.Now(), Status: status}
+	s.mu.Unlock()
+
+	s.logs.publish(event)
+}
+
+// collects what a commander function logs into its result and the log stream
+type resultWriter struct {
+	s     *Server
+	run   *Run
+	index int
+}
+
+func (w resultWriter) Write(p []byte) (int, error) {
+	now := time.Now()
+	for _, line := range strings.Split(strings.TrimSuffix(string(p), "\n"), "\n") {
+		w.s.mu.Lock()
+		result := &w.run.Results[w.index]
+		result.Logs = append(result.Logs, LogLine{Time: now, Line: line})
+		event := LogEvent{Run: w.run.ID, Result: w.index, Name: result.Name, Time: now, Line: line}
+		w.s.mu.Unlock()
+
+		w.s.logs.publish(event)
+	}
+	return len(p), nil
+}
+
+func (s *Server) handleRuns(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, s.runs)
+}
+
+func (s *Server) handleRun(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil || id < 1 || id > len(s.runs) {
+		writeError(w, http.StatusNotFound, "no run %q", r.PathValue("id"))
+		return
+	}
+	writeJSON(w, http.StatusOK, s.runs[id-1])
+}
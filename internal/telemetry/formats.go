@@ -0,0 +1,261 @@
+package telemetry
+
+import (
+	"UCLA-Rocket-Project/ILAYE/internal/commander"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// rows buffered in memory before a parquet file gets another row group
+const PARQUET_ROW_GROUP_ROWS = 10000
+
+// one decoded reading of a sensor as every format writes it
+type row struct {
+	// zero for readings decoded offline, e.g. from an SD card
+	hostTime time.Time
+
+	// from the telemetry header, polled replies only carry the timestamps of their own struct
+	boardMicros  int64
+	hasBoardTime bool
+
+	sequence uint64
+	fields   []commander.DecodedField
+}
+
+// numeric fields are written as numbers, anything else as the string the decoder shows
+func fieldValues(fields []commander.DecodedField) map[string]any {
+	values := map[string]any{}
+	for _, field := range fields {
+		if value, err := strconv.ParseFloat(field.Value, 64); err == nil {
+			values[field.Name] = value
+		} else {
+			values[field.Name] = field.Value
+		}
+	}
+	return values
+}
+
+// one open file of a sensor, the columns are taken from the first row written to it
+type sensorFile interface {
+	write(r row) error
+	// bytes on disk so far, used to rotate
+	size() int64
+	close() error
+}
+
+var formatExtensions = map[string]string{
+	"csv":     ".csv",
+	"parquet": ".parquet",
+	"jsonl":   ".jsonl",
+}
+
+func openSensorFile(format string, path string, first row) (sensorFile, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	counted := &countingFile{file: file}
+
+	switch format {
+	case "csv":
+		return newCSVFile(counted, first)
+	case "parquet":
+		return newParquetFile(counted, first), nil
+	case "jsonl":
+		return &jsonlFile{countingFile: counted, enc: json.NewEncoder(counted)}, nil
+	}
+	file.Close()
+	return nil, fmt.Errorf("unknown recording format %q", format)
+}
+
+type countingFile struct {
+	file    *os.File
+	written int64
+}
+
+func (f *countingFile) Write(p []byte) (int, error) {
+	n, err := f.file.Write(p)
+	f.written += int64(n)
+	return n, err
+}
+
+func (f *countingFile) size() int64 {
+	return f.written
+}
+
+// host_time, board_micros and sequence, then one column per field, flushed on every row
+type csvFile struct {
+	*countingFile
+	w       *csv.Writer
+	columns []string
+}
+
+func newCSVFile(file *countingFile, first row) (*csvFile, error) {
+	f := &csvFile{countingFile: file, w: csv.NewWriter(file)}
+	for _, field := range first.fields {
+		f.columns = append(f.columns, field.Name)
+	}
+	if err := f.w.Write(append([]string{"host_time", "board_micros", "sequence"}, f.columns...)); err != nil {
+		file.file.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *csvFile) write(r row) error {
+	record := []string{"", "", strconv.FormatUint(r.sequence, 10)}
+	if !r.hostTime.IsZero() {
+		record[0] = r.hostTime.UTC().Format(time.RFC3339Nano)
+	}
+	if r.hasBoardTime {
+		record[1] = strconv.FormatInt(r.boardMicros, 10)
+	}
+
+	values := map[string]string{}
+	for _, field := range r.fields {
+		values[field.Name] = field.Value
+	}
+	for _, column := range f.columns {
+		record = append(record, values[column])
+	}
+
+	if err := f.w.Write(record); err != nil {
+		return err
+	}
+	f.w.Flush()
+	return f.w.Error()
+}
+
+func (f *csvFile) close() error {
+	f.w.Flush()
+	if err := f.w.Error(); err != nil {
+		f.file.Close()
+		return err
+	}
+	return f.file.Close()
+}
+
+// the same columns as CSV, a field is a double when it was a number in the first row and a string otherwise
+type parquetFile struct {
+	*countingFile
+	w        *parquet.Writer
+	columns  []string
+	numeric  map[string]bool
+	buffered int
+}
+
+func newParquetFile(file *countingFile, first row) *parquetFile {
+	f := &parquetFile{countingFile: file, numeric: map[string]bool{}}
+
+	group := parquet.Group{
+		"host_time":    parquet.Optional(parquet.Timestamp(parquet.Microsecond)),
+		"board_micros": parquet.Optional(parquet.Int(64)),
+		"sequence":     parquet.Uint(64),
+	}
+	for name, value := range fieldValues(first.fields) {
+		if _, ok := value.(float64); ok {
+			f.numeric[name] = true
+			group[name] = parquet.Optional(parquet.Leaf(parquet.DoubleType))
+		} else {
+			group[name] = parquet.Optional(parquet.String())
+		}
+	}
+
+	schema := parquet.NewSchema("reading", group)
+	for _, column := range schema.Columns() {
+		f.columns = append(f.columns, column[0])
+	}
+	f.w = parquet.NewWriter(file, schema)
+	return f
+}
+
+func (f *parquetFile) write(r row) error {
+	values := fieldValues(r.fields)
+
+	record := make(parquet.Row, 0, len(f.columns))
+	for i, column := range f.columns {
+		switch column {
+		case "host_time":
+			if r.hostTime.IsZero() {
+				record = append(record, parquet.NullValue().Level(0, 0, i))
+			} else {
+				record = append(record, parquet.ValueOf(r.hostTime.UnixMicro()).Level(0, 1, i))
+			}
+		case "sequence":
+			record = append(record, parquet.ValueOf(r.sequence).Level(0, 0, i))
+		case "board_micros":
+			if r.hasBoardTime {
+				record = append(record, parquet.ValueOf(r.boardMicros).Level(0, 1, i))
+			} else {
+				record = append(record, parquet.NullValue().Level(0, 0, i))
+			}
+		default:
+			// a field missing from this row, or no longer a number, is left null
+			value, ok := values[column]
+			number, isNumber := value.(float64)
+			switch {
+			case !ok:
+				record = append(record, parquet.NullValue().Level(0, 0, i))
+			case f.numeric[column] && isNumber:
+				record = append(record, parquet.ValueOf(number).Level(0, 1, i))
+			case f.numeric[column]:
+				record = append(record, parquet.NullValue().Level(0, 0, i))
+			default:
+				record = append(record, parquet.ValueOf(fmt.Sprint(value)).Level(0, 1, i))
+			}
+		}
+	}
+
+	if _, err := f.w.WriteRows([]parquet.Row{record}); err != nil {
+		return err
+	}
+	f.buffered++
+	if f.buffered >= PARQUET_ROW_GROUP_ROWS {
+		f.buffered = 0
+		return f.w.Flush()
+	}
+	return nil
+}
+
+func (f *parquetFile) close() error {
+	if err := f.w.Close(); err != nil {
+		f.file.Close()
+		return err
+	}
+	return f.file.Close()
+}
+
+// one line of a JSON lines recording
+type jsonReading struct {
+	HostTime    *time.Time     `json:"host_time"`
+	BoardMicros *int64         `json:"board_micros"`
+	Sequence    uint64         `json:"sequence"`
+	Fields      map[string]any `json:"fields"`
+}
+
+// one JSON object per line, unbuffered so a crash loses nothing
+type jsonlFile struct {
+	*countingFile
+	enc *json.Encoder
+}
+
+func (f *jsonlFile) write(r row) error {
+	reading := jsonReading{Sequence: r.sequence, Fields: fieldValues(r.fields)}
+	if !r.hostTime.IsZero() {
+		reading.HostTime = &r.hostTime
+	}
+	if r.hasBoardTime {
+		reading.BoardMicros = &r.boardMicros
+	}
+	return f.enc.Encode(reading)
+}
+
+func (f *jsonlFile) close() error {
+	return f.file.Close()
+}
@@ -0,0 +1,199 @@
+package telemetry
+
+import (
+	"UCLA-Rocket-Project/ILAYE/internal/commander"
+	"UCLA-Rocket-Project/ILAYE/internal/config"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// a directory name for a recording of label, e.g. polled_dev_ttyusb0_20250301-101500
+func SessionName(prefix string, label string, at time.Time) string {
+	label = strings.ToLower(strings.Trim(unsafeNameChars.ReplaceAllString(label, "_"), "_"))
+	return fmt.Sprintf("%s_%s_%s", prefix, label, at.Format("20060102-150405"))
+}
+
+// the open files of one sensor, part counts the rotations
+type sensorFiles struct {
+	part   int
+	opened time.Time
+	files  []sensorFile
+}
+
+// a directory of per sensor files in every configured format, for streamed packets and polled replies alike
+type SessionRecorder struct {
+	Dir string
+
+	formats     []string
+	rotateSize  int64
+	rotateEvery time.Duration
+	log         *zap.Logger
+
+	mu       sync.Mutex
+	sensors  map[string]*sensorFiles
+	sequence map[byte]uint64
+}
+
+// nothing is created on disk until the first reading arrives
+func NewSessionRecorder(cfg config.TelemetryConfig, name string, log *zap.Logger) (*SessionRecorder, error) {
+	if len(cfg.Formats) == 0 {
+		return nil, errors.New("no recording formats configured")
+	}
+	for _, format := range cfg.Formats {
+		if _, ok := formatExtensions[format]; !ok {
+			return nil, fmt.Errorf("unknown recording format %q, expected csv, parquet or jsonl", format)
+		}
+	}
+
+	return &SessionRecorder{
+		Dir:         filepath.Join(cfg.RecordDir, name),
+		formats:     cfg.Formats,
+		rotateSize:  cfg.RotateSize,
+		rotateEvery: cfg.RotateEvery,
+		log:         log,
+		sensors:     map[string]*sensorFiles{},
+		sequence:    map[byte]uint64{},
+	}, nil
+}
+
+func (r *SessionRecorder) Record(receivedAt time.Time, packet *commander.TelemetryPacket) error {
+	return r.write(packet.Name, row{
+		hostTime:     receivedAt,
+		boardMicros:  packet.BoardMicros,
+		hasBoardTime: true,
+		sequence:     uint64(packet.Sequence),
+		fields:       packet.Decoded.Fields,
+	})
+}
+
+// a reading decoded offline, e.g. from an SD card, without a host or board time of its own
+func (r *SessionRecorder) RecordDecoded(sensor string, sequence uint64, decoded *commander.Decoded) error {
+	return r.write(sensor, row{sequence: sequence, fields: decoded.Fields})
+}
+
+func (r *SessionRecorder) Sent(opcode byte, at time.Time) {}
+
+// every decoded reading polled over the connection, the sequence counts the replies of each command
+func (r *SessionRecorder) Replied(reply commander.Reply) {
+	if !isReading(reply) {
+		return
+	}
+	name, ok := commander.OpcodeName(reply.Opcode)
+	if !ok {
+		name = fmt.Sprintf("0x%02X", reply.Opcode)
+	}
+
+	r.mu.Lock()
+	sequence := r.sequence[reply.Opcode]
+	r.sequence[reply.Opcode]++
+	r.mu.Unlock()
+
+	err := r.write(name, row{hostTime: reply.ReceivedAt, sequence: sequence, fields: reply.Decoded.Fields})
+	if err != nil {
+		r.log.Error("Could not record reply", zap.Error(err), zap.String("command", name))
+	}
+}
+
+// a reply carrying readings, acknowledgements and error codes are left out
+func isReading(reply commander.Reply) bool {
+	if reply.Err != nil || reply.Decoded == nil || reply.Decoded.Layout == "ackReply" || len(reply.Raw) == 0 {
+		return false
+	}
+	name, isError := commander.ErrorCodeName(reply.Raw[0])
+	return !isError || reply.Decoded.Layout != name
+}
+
+func (r *SessionRecorder) write(sensor string, reading row) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	files, ok := r.sensors[sensor]
+	if ok && r.due(files) {
+		err := closeAll(files.files)
+		files.files = nil
+		if err != nil {
+			return err
+		}
+	}
+	if !ok {
+		files = &sensorFiles{}
+		r.sensors[sensor] = files
+	}
+
+	if files.files == nil {
+		if err := r.open(sensor, files, reading); err != nil {
+			return err
+		}
+	}
+
+	var errs []error
+	for _, file := range files.files {
+		errs = append(errs, file.write(reading))
+	}
+	return errors.Join(errs...)
+}
+
+// whether the files of a sensor grew past the size or age limit
+func (r *SessionRecorder) due(files *sensorFiles) bool {
+	if r.rotateEvery > 0 && time.Since(files.opened) >= r.rotateEvery {
+		return true
+	}
+	for _, file := range files.files {
+		if r.rotateSize > 0 && file.size() >= r.rotateSize {
+			return true
+		}
+	}
+	return false
+}
+
+// the next part of a sensor in every format, e.g. tlm_digital_imu_002.csv
+func (r *SessionRecorder) open(sensor string, files *sensorFiles, first row) error {
+	if err := os.MkdirAll(r.Dir, 0o755); err != nil {
+		return err
+	}
+
+	files.part++
+	files.opened = time.Now()
+	base := fmt.Sprintf("%s_%03d", strings.ToLower(unsafeNameChars.ReplaceAllString(sensor, "_")), files.part)
+	for _, format := range r.formats {
+		file, err := openSensorFile(format, filepath.Join(r.Dir, base+formatExtensions[format]), first)
+		if err != nil {
+			closeAll(files.files)
+			files.files = nil
+			return err
+		}
+		files.files = append(files.files, file)
+	}
+	return nil
+}
+
+func closeAll(files []sensorFile) error {
+	var errs []error
+	for _, file := range files {
+		errs = append(errs, file.close())
+	}
+	return errors.Join(errs...)
+}
+
+// finish every open file, parquet footers are only written here
+func (r *SessionRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var errs []error
+	for _, files := range r.sensors {
+		errs = append(errs, closeAll(files.files))
+		files.files = nil
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,180 @@
+/**
+Passive telemetry for static fires and pad operations
+
+The packet format is ILAYE's proposal in protocol/protocol.yaml, no board
+firmware streams it yet so the TUI only offers it with telemetry.proposed_stream
+set. Once boards stream it in normal mode, a stream takes
+every frame the port's reader could not match to a command, decodes the known
+telemetry types into the same structs the checks use, keeps per packet
+statistics for the TUI and hands each packet to a recorder. Nothing is sent.
+*/
+
+package telemetry
+
+import (
+	"UCLA-Rocket-Project/ILAYE/internal/commander"
+	"UCLA-Rocket-Project/ILAYE/internal/rpSerial"
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// packets the rate is averaged over
+const RATE_WINDOW = 20
+
+// what a stream has seen of one packet type
+type Stats struct {
+	Name  string
+	Board string
+	Count int
+
+	// packets missing from the sequence numbers, e.g. lost over the radio
+	Gaps int
+
+	Last     *commander.TelemetryPacket
+	LastAt   time.Time
+	RateHz   float64
+	arrivals []time.Time
+}
+
+// where decoded packets are written, a stream records into one while it runs
+type Recorder interface {
+	Record(receivedAt time.Time, packet *commander.TelemetryPacket) error
+	Close() error
+}
+
+type Stream struct {
+	recorder  Recorder
+	observers []commander.TelemetryObserver
+	log       *zap.Logger
+	stop      chan struct{}
+	done      chan struct{}
+
+	mu        sync.Mutex
+	stats     map[byte]*Stats
+	undecoded int
+	started   time.Time
+}
+
+// the frames nobody asked for on conn, found through the wrappers the connector adds
+func Frames(conn commander.SerialReaderWriter) (<-chan rpSerial.UnmatchedFrame, error) {
+	for {
+		switch c := conn.(type) {
+		case *rpSerial.RpSerial:
+			return c.Unmatched(), nil
+		case interface {
+			Unwrap() commander.SerialReaderWriter
+		}:
+			conn = c.Unwrap()
+		default:
+			return nil, errors.New("this connection has no frame reader to listen on")
+		}
+	}
+}
+
+// decode frames until Stop, recorder may be nil to only watch, observers such as the alarm engine see every packet
+func Listen(frames <-chan rpSerial.UnmatchedFrame, recorder Recorder, log *zap.Logger, observers ...commander.TelemetryObserver) *Stream {
+	s := &Stream{
+		recorder:  recorder,
+		observers: observers,
+		log:       log,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+		stats:     map[byte]*Stats{},
+		started:   time.Now(),
+	}
+	go s.run(frames)
+	return s
+}
+
+func (s *Stream) run(frames <-chan rpSerial.UnmatchedFrame) {
+	defer close(s.done)
+	for {
+		select {
+		case <-s.stop:
+			return
+		case frame := <-frames:
+			s.handle(frame)
+		}
+	}
+}
+
+func (s *Stream) handle(frame rpSerial.UnmatchedFrame) {
+	packet, err := commander.DecodeTelemetry(frame.Data)
+	if err != nil {
+		s.mu.Lock()
+		s.undecoded++
+		s.mu.Unlock()
+		return
+	}
+
+	s.mu.Lock()
+	stats, ok := s.stats[packet.Type]
+	if !ok {
+		stats = &Stats{Name: packet.Name, Board: packet.Board}
+		s.stats[packet.Type] = stats
+	} else if missed := packet.Sequence - stats.Last.Sequence - 1; missed != 0 && missed < 0x8000 {
+		// sequence numbers wrap, a packet from the past is a repeat rather than a gap
+		stats.Gaps += int(missed)
+	}
+	stats.Count++
+	stats.Last = packet
+	stats.LastAt = frame.At
+	stats.arrivals = append(stats.arrivals, frame.At)
+	if len(stats.arrivals) > RATE_WINDOW {
+		stats.arrivals = stats.arrivals[1:]
+	}
+	if span := stats.arrivals[len(stats.arrivals)-1].Sub(stats.arrivals[0]); span > 0 {
+		stats.RateHz = float64(len(stats.arrivals)-1) / span.Seconds()
+	}
+	s.mu.Unlock()
+
+	for _, observer := range s.observers {
+		observer.Packet(frame.At, packet)
+	}
+
+	if s.recorder != nil {
+		if err := s.recorder.Record(frame.At, packet); err != nil {
+			s.log.Error("Could not record telemetry", zap.Error(err), zap.String("packet", packet.Name))
+		}
+	}
+}
+
+// a copy of the statistics of every packet type seen, in schema order
+func (s *Stream) Stats() []Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := []Stats{}
+	for _, packetType := range commander.TelemetryTypes() {
+		if st, ok := s.stats[packetType]; ok {
+			copied := *st
+			copied.arrivals = nil
+			stats = append(stats, copied)
+		}
+	}
+	return stats
+}
+
+// frames that were not telemetry, or telemetry of the wrong size
+func (s *Stream) Undecoded() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.undecoded
+}
+
+func (s *Stream) Started() time.Time {
+	return s.started
+}
+
+// stop listening and close the recorder, the port keeps its reader for the next stream
+func (s *Stream) Stop() error {
+	close(s.stop)
+	<-s.done
+	if s.recorder != nil {
+		return s.recorder.Close()
+	}
+	return nil
+}
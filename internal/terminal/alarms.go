@@ -0,0 +1,163 @@
+package terminal
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// the banner flashes on every tick while an alarm is unacknowledged
+const ALARM_TICK_INTERVAL = 500 * time.Millisecond
+
+// how often the terminal bell rings until every alarm is acknowledged
+const ALARM_BELL_INTERVAL = 2 * time.Second
+
+type alarmTickMsg struct{}
+
+func alarmTick() tea.Cmd {
+	return tea.Tick(ALARM_TICK_INTERVAL, func(time.Time) tea.Msg {
+		return alarmTickMsg{}
+	})
+}
+
+func ringBell() tea.Msg {
+	os.Stdout.WriteString("\a")
+	return nil
+}
+
+// the banner takes a line above every tab once any rule is loaded
+func (a *app) alarmsArmed() bool {
+	return a.alarms != nil && len(a.alarms.Rules()) > 0
+}
+
+func (a *app) updateAlarmTick() tea.Cmd {
+	a.alarmFlash = !a.alarmFlash
+	if a.alarms == nil || a.alarms.Unacknowledged() == 0 {
+		return alarmTick()
+	}
+
+	if time.Since(a.lastBell) >= ALARM_BELL_INTERVAL {
+		a.lastBell = time.Now()
+		return tea.Batch(alarmTick(), ringBell)
+	}
+	return alarmTick()
+}
+
+func (m model) openAlarms() model {
+	m.alarmsReturn = m.uiState
+	m.alarmCursor = 0
+	m.uiState = VIEW_ALARMS
+	return m
+}
+
+func (m model) updateAlarms(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	latched := m.alarms.Alarms()
+	switch keyMsg.String() {
+	case "up", "k":
+		if m.alarmCursor > 0 {
+			m.alarmCursor--
+		}
+	case "down", "j":
+		if m.alarmCursor < len(latched)-1 {
+			m.alarmCursor++
+		}
+	case "a":
+		if m.alarmCursor < len(latched) {
+			m.alarms.Acknowledge(latched[m.alarmCursor].Rule, latched[m.alarmCursor].Link)
+		}
+	case "A":
+		m.alarms.AcknowledgeAll()
+	case "c":
+		if m.alarmCursor < len(latched) {
+			if err := m.alarms.Clear(latched[m.alarmCursor].Rule, latched[m.alarmCursor].Link); err != nil {
+				m.err = err
+				return m, nil
+			}
+			m.err = nil
+			m.alarmCursor = max(min(m.alarmCursor, len(latched)-2), 0)
+		}
+	case "b", "esc", "!":
+		m.err = nil
+		m.uiState = m.alarmsReturn
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// one line on top of every tab, red and flashing until the operator acknowledges
+func (a *app) viewAlarmBanner() string {
+	if !a.alarmsArmed() {
+		return ""
+	}
+
+	latched := a.alarms.Alarms()
+	unacked := 0
+	var latest string
+	for _, alarm := range latched {
+		if !alarm.Acked {
+			unacked++
+			latest = fmt.Sprintf("%s on %s: %s", alarm.Rule, alarm.Link, alarm.Message)
+		}
+	}
+
+	switch {
+	case unacked > 0:
+		style := errorStyle
+		if a.alarmFlash {
+			style = alarmStyle
+		}
+		return style.Render(fmt.Sprintf(" ⚠ ALARM (%d unacknowledged) %s • ! to acknowledge ", unacked, latest)) + "\n"
+	case len(latched) > 0:
+		return errorStyle.Render(fmt.Sprintf(" ⚠ %d acknowledged alarms latched • ! to review ", len(latched))) + "\n"
+	}
+	return mutedStyle.Render(fmt.Sprintf(" ✓ %d alarm rules armed", len(a.alarms.Rules()))) + "\n"
+}
+
+func (m model) viewAlarms() string {
+	var s strings.Builder
+
+	header := headerStyle.Render("▸ Alarms")
+	s.WriteString(header + "\n")
+	s.WriteString("  " + mutedStyle.Render(fmt.Sprintf("%d rules from %s • logged to %s", len(m.alarms.Rules()), m.config.Alarms.File, m.config.Alarms.LogFile)) + "\n\n")
+
+	latched := m.alarms.Alarms()
+	if len(latched) == 0 {
+		s.WriteString("  " + successStyle.Render("✓ no alarms") + "\n")
+	}
+	for i, alarm := range latched {
+		cursor := renderCursor(i == m.alarmCursor)
+
+		state := "normal"
+		if alarm.Active {
+			state = "ACTIVE"
+		}
+		if !alarm.Acked {
+			state += ", unacknowledged"
+		}
+		line := fmt.Sprintf("%s  %-28s %-16s %-22s %s", alarm.Raised.Format("15:04:05"), alarm.Rule, alarm.Link, state, alarm.Message)
+
+		switch {
+		case !alarm.Acked || alarm.Active:
+			line = errorStyle.Render(line)
+		case i == m.alarmCursor:
+			line = selectedItemStyle.Render(line)
+		default:
+			line = normalItemStyle.Render(line)
+		}
+		s.WriteString(fmt.Sprintf("  %s %s\n", cursor, line))
+	}
+
+	s.WriteString("\n")
+	s.WriteString(renderHint("  ↑/↓ navigate • a acknowledge • A acknowledge all • c clear once normal • b back"))
+
+	return s.String()
+}
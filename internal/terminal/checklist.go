@@ -0,0 +1,357 @@
+package terminal
+
+import (
+	"UCLA-Rocket-Project/ILAYE/internal/checkout"
+	"UCLA-Rocket-Project/ILAYE/internal/procedure"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"gopkg.in/yaml.v3"
+)
+
+type checklistStage int
+
+const (
+	CHECKLIST_READY checklistStage = iota
+	CHECKLIST_RUNNING
+	CHECKLIST_FAILED
+	CHECKLIST_OVERRIDE_REASON
+	CHECKLIST_INITIALS
+	CHECKLIST_NOTES
+	CHECKLIST_DONE
+)
+
+// sign-off for one checklist item, the status and logs live in model.results
+type checklistSignoff struct {
+	Initials       string
+	Notes          string
+	OverrideReason string
+	SignedAt       time.Time
+}
+
+type checklistState struct {
+	procedure *procedure.Procedure
+	signoffs  []checklistSignoff
+	current   int
+	stage     checklistStage
+	input     textinput.Model
+	startedAt time.Time
+
+	// where the signed record was written once the checklist is complete
+	recordPath string
+}
+
+func (c *checklistState) step() procedure.Step {
+	return c.procedure.Steps[c.current]
+}
+
+func (c *checklistState) prompt(placeholder string) tea.Cmd {
+	c.input.Reset()
+	c.input.Placeholder = placeholder
+	return c.input.Focus()
+}
+
+// the checklist for the selected section, loaded and checked against its menus
+func (m model) loadChecklist() (*checklistState, error) {
+	path := m.config.Sections.BodyTube.Checklist
+	if m.selectedSection == checkout.SECTION_NOSE_CONE {
+		path = m.config.Sections.NoseCone.Checklist
+	}
+	proc, err := procedure.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	section, _ := proc.CheckoutSection()
+	if section != m.selectedSection {
+		return nil, fmt.Errorf("%s is a %s checklist", path, section)
+	}
+	if err := proc.Validate(m.checkout()); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	input := textinput.New()
+	input.CharLimit = 200
+
+	return &checklistState{
+		procedure: proc,
+		signoffs:  make([]checklistSignoff, len(proc.Steps)),
+		input:     input,
+		startedAt: time.Now(),
+	}, nil
+}
+
+type checklistRecordItem struct {
+	Item           string    `yaml:"item"`
+	Kind           string    `yaml:"kind"`
+	Result         string    `yaml:"result"`
+	OverrideReason string    `yaml:"override_reason,omitempty"`
+	Initials       string    `yaml:"initials"`
+	Notes          string    `yaml:"notes,omitempty"`
+	SignedAt       time.Time `yaml:"signed_at"`
+	Logs           []string  `yaml:"logs,omitempty"`
+}
+
+type checklistRecord struct {
+	Checklist  string                `yaml:"checklist"`
+	Section    string                `yaml:"section"`
+	Port       string                `yaml:"port"`
+	StartedAt  time.Time             `yaml:"started_at"`
+	FinishedAt time.Time             `yaml:"finished_at"`
+	Items      []checklistRecordItem `yaml:"items"`
+}
+
+// write the signed checklist next to the session log for the launch readiness record
+func (m model) saveChecklist() (string, error) {
+	c := m.checklist
+	record := checklistRecord{
+		Checklist:  c.procedure.Name,
+		Section:    m.selectedSection.String(),
+		Port:       m.portName,
+		StartedAt:  c.startedAt,
+		FinishedAt: time.Now(),
+	}
+
+	for i, step := range c.procedure.Steps {
+		item := checklistRecordItem{
+			Item:           step.Describe(),
+			Kind:           "automated",
+			Result:         "pass",
+			OverrideReason: c.signoffs[i].OverrideReason,
+			Initials:       c.signoffs[i].Initials,
+			Notes:          c.signoffs[i].Notes,
+			SignedAt:       c.signoffs[i].SignedAt,
+		}
+		if step.IsManual() {
+			item.Kind = "manual"
+			item.Result = "acknowledged"
+		} else if m.results[i].Status == StatusFail {
+			item.Result = "fail (overridden)"
+		}
+		for _, log := range m.results[i].Logs {
+			item.Logs = append(item.Logs, log.Timestamp.Format("15:04:05.000")+" "+strings.TrimSuffix(log.Content, "\n"))
+		}
+		record.Items = append(record.Items, item)
+	}
+
+	contents, err := yaml.Marshal(record)
+	if err != nil {
+		return "", err
+	}
+
+	dir := m.config.Checklist.RecordDir
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("checklist_%s.yaml", c.startedAt.Format("20060102-150405")))
+	return path, os.WriteFile(path, contents, 0644)
+}
+
+// run the automated step under the cursor in the background, like the test runner
+func (m model) runChecklistStep() (model, tea.Cmd) {
+	m.checklist.stage = CHECKLIST_RUNNING
+	m.results[m.checklist.current].Logs = []LogEntry{}
+	m.logChan = make(chan any)
+
+	index := m.checklist.current
+	step := m.checklist.step()
+	runner := m.checkout()
+	go func() {
+		defer close(m.logChan)
+		w := &chanWriter{ch: m.logChan}
+		w.ch <- TestStartMsg{Index: index}
+		// automated steps never prompt, manual ones are acknowledged in the TUI
+		success := procedure.RunStep(runner, w, nil, step)
+		w.ch <- TestResultMsg{Index: index, Success: success}
+	}()
+
+	return m, tea.Batch(waitForLog(m.logChan), m.spinner.Tick)
+}
+
+// called once the automated step reports back, a failure blocks until overridden or retried
+func (m model) checklistStepFinished(msg TestResultMsg) model {
+	if m.checklist == nil || msg.Index != m.checklist.current {
+		return m
+	}
+	if msg.Success {
+		m.checklist.stage = CHECKLIST_INITIALS
+		m.checklist.prompt("initials")
+	} else {
+		m.checklist.stage = CHECKLIST_FAILED
+	}
+	return m
+}
+
+func (m model) updateChecklist(msg tea.Msg) (tea.Model, tea.Cmd) {
+	c := m.checklist
+	keyMsg, isKey := msg.(tea.KeyMsg)
+
+	switch c.stage {
+	case CHECKLIST_READY:
+		if !isKey || keyMsg.String() != "enter" {
+			break
+		}
+		if c.step().IsManual() {
+			m.results[c.current].Status = StatusPass
+			c.stage = CHECKLIST_INITIALS
+			return m, c.prompt("initials")
+		}
+		return m.runChecklistStep()
+
+	case CHECKLIST_FAILED:
+		if !isKey {
+			break
+		}
+		switch keyMsg.String() {
+		case "r":
+			return m.runChecklistStep()
+		case "o":
+			c.stage = CHECKLIST_OVERRIDE_REASON
+			return m, c.prompt("reason for overriding this failure")
+		}
+
+	case CHECKLIST_OVERRIDE_REASON, CHECKLIST_INITIALS, CHECKLIST_NOTES:
+		if isKey && keyMsg.String() == "esc" && c.stage == CHECKLIST_OVERRIDE_REASON {
+			c.input.Blur()
+			c.stage = CHECKLIST_FAILED
+			return m, nil
+		}
+		if !isKey || keyMsg.String() != "enter" {
+			var cmd tea.Cmd
+			c.input, cmd = c.input.Update(msg)
+			return m, cmd
+		}
+
+		value := strings.TrimSpace(c.input.Value())
+		switch c.stage {
+		case CHECKLIST_OVERRIDE_REASON:
+			if value == "" {
+				return m, nil
+			}
+			c.signoffs[c.current].OverrideReason = value
+			c.stage = CHECKLIST_INITIALS
+			return m, c.prompt("initials")
+		case CHECKLIST_INITIALS:
+			if value == "" {
+				return m, nil
+			}
+			c.signoffs[c.current].Initials = value
+			c.stage = CHECKLIST_NOTES
+			return m, c.prompt("notes (optional)")
+		case CHECKLIST_NOTES:
+			c.signoffs[c.current].Notes = value
+			c.signoffs[c.current].SignedAt = time.Now()
+			c.input.Blur()
+
+			if c.current < len(c.procedure.Steps)-1 {
+				c.current++
+				c.stage = CHECKLIST_READY
+				return m, nil
+			}
+
+			c.stage = CHECKLIST_DONE
+			path, err := m.saveChecklist()
+			if err != nil {
+				m.err = fmt.Errorf("could not save signed checklist: %w", err)
+			} else {
+				c.recordPath = path
+			}
+			return m, nil
+		}
+
+	case CHECKLIST_DONE:
+		if isKey && keyMsg.String() == "b" {
+			m.uiState = VIEW_SELECT_MODE
+			m.cursor = 0
+			m.checklist = nil
+			m.results = nil
+			return m, nil
+		}
+	}
+
+	return m, nil
+}
+
+func (m model) viewChecklist() string {
+	var s strings.Builder
+	c := m.checklist
+
+	header := headerStyle.Render(fmt.Sprintf("▸ %s (%s)", c.procedure.Name, m.selectedSection))
+	s.WriteString(header + "\n\n")
+
+	for i, step := range c.procedure.Steps {
+		icon := renderStatusIcon(m.results[i].Status, m.spinner.View())
+		kind := mutedStyle.Render("auto  ")
+		if step.IsManual() {
+			kind = mutedStyle.Render("manual")
+		}
+
+		name := renderTestName(step.Describe(), m.results[i].Status)
+		if i == c.current && c.stage != CHECKLIST_DONE {
+			name = selectedItemStyle.Render(step.Describe())
+		}
+
+		signoff := ""
+		if c.signoffs[i].Initials != "" {
+			signoff = mutedStyle.Render(fmt.Sprintf("  signed %s %s", c.signoffs[i].Initials, c.signoffs[i].SignedAt.Format("15:04")))
+		}
+		if c.signoffs[i].OverrideReason != "" {
+			signoff += errorStyle.Render("  overridden: " + c.signoffs[i].OverrideReason)
+		}
+
+		s.WriteString(fmt.Sprintf("  %s %s  %s  %s%s\n", renderCursor(i == c.current && c.stage != CHECKLIST_DONE), icon, kind, name, signoff))
+	}
+	s.WriteString("\n")
+
+	if c.stage != CHECKLIST_DONE {
+		step := c.step()
+		if step.IsManual() {
+			s.WriteString(fmt.Sprintf("  %s\n\n", runningStyle.Render(step.Prompt)))
+		}
+
+		for _, log := range m.results[c.current].Logs {
+			timestamp := timestampStyle.Render(log.Timestamp.Format("15:04:05.000"))
+			for j, line := range strings.Split(strings.TrimSuffix(log.Content, "\n"), "\n") {
+				if j == 0 {
+					s.WriteString(fmt.Sprintf("     %s  %s\n", timestamp, renderLogLine(line)))
+				} else {
+					s.WriteString(fmt.Sprintf("     %s  %s\n", strings.Repeat(" ", 12), renderLogLine(line)))
+				}
+			}
+		}
+	}
+
+	switch c.stage {
+	case CHECKLIST_READY:
+		if c.step().IsManual() {
+			s.WriteString(renderHint("  enter acknowledge • q quit"))
+		} else {
+			s.WriteString(renderHint("  enter run check • q quit"))
+		}
+	case CHECKLIST_RUNNING:
+		s.WriteString(renderHint("  running..."))
+	case CHECKLIST_FAILED:
+		s.WriteString(errorStyle.Render("  Check failed, the checklist cannot continue until it passes or is overridden") + "\n\n")
+		s.WriteString(renderHint("  r retry • o override with a reason • q quit"))
+	case CHECKLIST_OVERRIDE_REASON, CHECKLIST_INITIALS, CHECKLIST_NOTES:
+		s.WriteString("\n  " + c.input.View() + "\n\n")
+		if c.stage == CHECKLIST_OVERRIDE_REASON {
+			s.WriteString(renderHint("  enter confirm • esc cancel override • ctrl+c quit"))
+		} else {
+			s.WriteString(renderHint("  enter confirm • ctrl+c quit"))
+		}
+	case CHECKLIST_DONE:
+		s.WriteString(successStyle.Render("  Checklist complete") + "\n")
+		if c.recordPath != "" {
+			s.WriteString(mutedStyle.Render("  Signed record saved to "+c.recordPath) + "\n")
+		}
+		s.WriteString("\n" + renderHint("  b back to mode • q quit"))
+	}
+
+	return s.String()
+}
@@ -0,0 +1,177 @@
+package terminal
+
+import (
+	"UCLA-Rocket-Project/ILAYE/internal/commander"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// how many past exchanges stay on screen in the console
+const CONSOLE_HISTORY_SHOWN = 3
+
+type consoleEntry struct {
+	Input    string
+	Frame    []byte
+	Reply    []byte
+	Err      error
+	SentAt   time.Time
+	Duration time.Duration
+	Pending  bool
+}
+
+type consoleReplyMsg struct {
+	Reply    []byte
+	Err      error
+	Duration time.Duration
+}
+
+func newConsoleInput() textinput.Model {
+	input := textinput.New()
+	input.Prompt = "› "
+	input.Placeholder = "opcode or hex payload, e.g. 0xD5 or d5 01 02"
+	input.CharLimit = 512
+	return input
+}
+
+func (m model) openConsole() (model, tea.Cmd) {
+	m.uiState = VIEW_CONSOLE
+	m.consoleHistoryCursor = len(m.consoleEntries)
+	return m, m.console.Focus()
+}
+
+func sendRaw(conn SerialReaderWriter, frame []byte) tea.Cmd {
+	return func() tea.Msg {
+		sentAt := time.Now()
+		reply, err := commander.SendRaw(conn, frame)
+		return consoleReplyMsg{Reply: reply, Err: err, Duration: time.Since(sentAt)}
+	}
+}
+
+func (m model) updateConsole(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case consoleReplyMsg:
+		last := len(m.consoleEntries) - 1
+		if last >= 0 && m.consoleEntries[last].Pending {
+			m.consoleEntries[last].Reply = msg.Reply
+			m.consoleEntries[last].Err = msg.Err
+			m.consoleEntries[last].Duration = msg.Duration
+			m.consoleEntries[last].Pending = false
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		pending := len(m.consoleEntries) > 0 && m.consoleEntries[len(m.consoleEntries)-1].Pending
+
+		switch msg.String() {
+		case "esc":
+			if pending {
+				return m, nil
+			}
+			m.console.Blur()
+			m.uiState = VIEW_SELECT_MODE
+			m.cursor = 0
+			return m, nil
+		case "up":
+			// recall what was typed before, like a shell
+			if m.consoleHistoryCursor > 0 {
+				m.consoleHistoryCursor--
+				m.console.SetValue(m.consoleEntries[m.consoleHistoryCursor].Input)
+				m.console.CursorEnd()
+			}
+			return m, nil
+		case "down":
+			if m.consoleHistoryCursor < len(m.consoleEntries)-1 {
+				m.consoleHistoryCursor++
+				m.console.SetValue(m.consoleEntries[m.consoleHistoryCursor].Input)
+			} else {
+				m.consoleHistoryCursor = len(m.consoleEntries)
+				m.console.Reset()
+			}
+			m.console.CursorEnd()
+			return m, nil
+		case "enter":
+			if pending {
+				return m, nil
+			}
+			input := strings.TrimSpace(m.console.Value())
+			frame, err := commander.ParseFrame(input)
+			if err != nil {
+				m.consoleErr = err
+				return m, nil
+			}
+			m.consoleErr = nil
+
+			m.consoleEntries = append(m.consoleEntries, consoleEntry{Input: input, Frame: frame, SentAt: time.Now(), Pending: true})
+			m.consoleHistoryCursor = len(m.consoleEntries)
+			m.console.Reset()
+			return m, tea.Batch(sendRaw(m.serial, frame), m.spinner.Tick)
+		}
+	}
+
+	var cmd tea.Cmd
+	m.console, cmd = m.console.Update(msg)
+	return m, cmd
+}
+
+func renderConsoleEntry(entry consoleEntry, spinnerView string) string {
+	var s strings.Builder
+
+	s.WriteString(fmt.Sprintf("  %s  %s\n", timestampStyle.Render(entry.SentAt.Format("15:04:05.000")), selectedItemStyle.Render("› "+entry.Input)))
+	s.WriteString(fmt.Sprintf("  %s\n", runningStyle.Render("Sent")))
+	s.WriteString(renderHexDump(entry.Frame))
+
+	switch {
+	case entry.Pending:
+		s.WriteString(fmt.Sprintf("  %s waiting for a reply\n", runningStyle.Render(spinnerView)))
+		return s.String()
+	case entry.Err != nil:
+		s.WriteString(errorStyle.Render(fmt.Sprintf("  No reply after %s: %s", entry.Duration.Round(time.Millisecond), entry.Err)) + "\n")
+		return s.String()
+	}
+
+	s.WriteString(fmt.Sprintf("  %s %s\n", runningStyle.Render("Received"), mutedStyle.Render(fmt.Sprintf("%d bytes in %s", len(entry.Reply), entry.Duration.Round(time.Millisecond)))))
+	s.WriteString(renderHexDump(entry.Reply))
+	if len(entry.Reply) == 0 {
+		return s.String()
+	}
+
+	// the known layout for the opcode first, otherwise every struct the reply fits
+	if decoded, err := commander.DecodeResponse(entry.Frame[0], entry.Reply); err == nil {
+		s.WriteString(renderDecoded(decoded))
+		return s.String()
+	}
+	candidates := commander.DecodeCandidates(entry.Reply)
+	if len(candidates) == 0 {
+		s.WriteString(mutedStyle.Render(fmt.Sprintf("     no known reply struct is %d bytes long", len(entry.Reply))) + "\n")
+	}
+	for _, decoded := range candidates {
+		s.WriteString(renderDecoded(decoded))
+	}
+
+	return s.String()
+}
+
+func (m model) viewConsole() string {
+	var s strings.Builder
+
+	header := headerStyle.Render("▸ Raw Command Console")
+	s.WriteString(header + "\n")
+	s.WriteString(mutedStyle.Render("  Frames are sent as typed with +++ appended, nothing is checked against the menus") + "\n\n")
+
+	first := max(0, len(m.consoleEntries)-CONSOLE_HISTORY_SHOWN)
+	for _, entry := range m.consoleEntries[first:] {
+		s.WriteString(renderConsoleEntry(entry, m.spinner.View()) + "\n")
+	}
+
+	if m.consoleErr != nil {
+		s.WriteString(errorStyle.Render("  "+m.consoleErr.Error()) + "\n")
+	}
+	s.WriteString("  " + m.console.View() + "\n\n")
+	s.WriteString(renderHint("  enter send • ↑/↓ history • esc back • ctrl+c quit"))
+
+	return s.String()
+}
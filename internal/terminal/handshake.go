@@ -0,0 +1,64 @@
+package terminal
+
+import (
+	"UCLA-Rocket-Project/ILAYE/internal/checkout"
+	"UCLA-Rocket-Project/ILAYE/internal/commander"
+	"UCLA-Rocket-Project/ILAYE/internal/config"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type handshakeMsg struct {
+	versions *commander.BoardVersions
+	ok       bool
+	logs     []string
+}
+
+// ask the radio for its protocol and board versions before anything else is sent
+func runHandshake(conn SerialReaderWriter, cfg *config.Config) tea.Cmd {
+	return func() tea.Msg {
+		var log strings.Builder
+		versions, ok := checkout.Handshake(conn, &log, cfg)
+		return handshakeMsg{
+			versions: versions,
+			ok:       ok,
+			logs:     strings.Split(strings.TrimSuffix(log.String(), "\n"), "\n"),
+		}
+	}
+}
+
+func (m model) updateHandshake(msg handshakeMsg) (tea.Model, tea.Cmd) {
+	m.versions = msg.versions
+	m.handshakeLogs = msg.logs
+
+	if !msg.ok {
+		m.err = fmt.Errorf("%s", msg.logs[len(msg.logs)-1])
+		m.recorder.Close()
+		delete(m.openPorts, m.portName)
+		m.serial = nil
+		m.recorder = nil
+		m.uiState = VIEW_LIST_PORTS
+		return m, nil
+	}
+
+	m.err = nil
+	m.cursor = 0
+	m.uiState = VIEW_SELECT_SECTION
+	return m, nil
+}
+
+// what the handshake found, shown under the section picker so the operator knows which menus to expect
+func (m model) viewVersions() string {
+	var s strings.Builder
+	if m.versions != nil {
+		s.WriteString("  " + mutedStyle.Render(m.versions.String()) + "\n")
+	}
+	for _, line := range m.handshakeLogs {
+		if strings.Contains(line, "WARNING") {
+			s.WriteString("  " + renderLogLine(line) + "\n")
+		}
+	}
+	return s.String()
+}
@@ -0,0 +1,164 @@
+package terminal
+
+import (
+	"UCLA-Rocket-Project/ILAYE/internal/commander"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// how many exchanges are listed above the selected one's details
+const INSPECTOR_LIST_SIZE = 8
+
+// open the raw inspector, coming back to from once closed
+func (m model) openInspector(from UIState) model {
+	m.inspectorReturn = from
+	m.inspectorCursor = len(m.recorder.Exchanges()) - 1
+	m.uiState = VIEW_INSPECTOR
+	return m
+}
+
+func (m model) updateInspector(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	count := len(m.recorder.Exchanges())
+	switch keyMsg.String() {
+	case "up", "k":
+		if m.inspectorCursor > 0 {
+			m.inspectorCursor--
+		}
+	case "down", "j":
+		if m.inspectorCursor < count-1 {
+			m.inspectorCursor++
+		}
+	case "home", "g":
+		m.inspectorCursor = 0
+	case "end", "G":
+		m.inspectorCursor = count - 1
+	case "b", "esc", "x":
+		m.uiState = m.inspectorReturn
+		if m.uiState == VIEW_SELECT_MODE {
+			m.cursor = 0
+		}
+	}
+
+	return m, nil
+}
+
+// 16 bytes per line with the offset in front, like xxd
+func renderHexDump(data []byte) string {
+	if len(data) == 0 {
+		return mutedStyle.Render("     (no bytes)") + "\n"
+	}
+
+	var s strings.Builder
+	for offset := 0; offset < len(data); offset += 16 {
+		end := min(offset+16, len(data))
+		s.WriteString(fmt.Sprintf("     %s  %s\n", timestampStyle.Render(fmt.Sprintf("%04x", offset)), logContentStyle.Render(fmt.Sprintf("% x", data[offset:end]))))
+	}
+	return s.String()
+}
+
+func renderDecoded(decoded *commander.Decoded) string {
+	var s strings.Builder
+
+	s.WriteString(fmt.Sprintf("     %s\n", mutedStyle.Render("decoded as "+decoded.Layout)))
+	s.WriteString(mutedStyle.Render(fmt.Sprintf("     %-6s %-24s %-28s %s", "offset", "bytes", "field", "value")) + "\n")
+	for _, field := range decoded.Fields {
+		s.WriteString(fmt.Sprintf("     %-6d %-24s %-28s %s\n", field.Offset, fmt.Sprintf("% x", field.Raw), field.Name, field.Value))
+	}
+	if len(decoded.Trailing) > 0 {
+		s.WriteString(errorStyle.Render(fmt.Sprintf("     %d trailing bytes not in %s: % x", len(decoded.Trailing), decoded.Layout, decoded.Trailing)) + "\n")
+	}
+
+	return s.String()
+}
+
+func renderExchangeSummary(exchange commander.Exchange) string {
+	at := exchange.SentAt
+	if at.IsZero() {
+		at = exchange.ReceivedAt
+	}
+
+	opcode := mutedStyle.Render("  -- ")
+	if len(exchange.Sent) > 0 {
+		opcode = fmt.Sprintf("0x%02X", exchange.Opcode())
+	}
+
+	reply := "waiting"
+	switch {
+	case exchange.Err != nil:
+		reply = "timed out"
+	case !exchange.ReceivedAt.IsZero():
+		reply = fmt.Sprintf("← %dB", len(exchange.Received))
+		if !exchange.SentAt.IsZero() {
+			reply += fmt.Sprintf(" in %s", exchange.ReceivedAt.Sub(exchange.SentAt).Round(time.Millisecond))
+		}
+	}
+
+	return fmt.Sprintf("%s  %s  → %dB  %s", at.Format("15:04:05.000"), opcode, len(exchange.Sent), reply)
+}
+
+func (m model) viewInspector() string {
+	var s strings.Builder
+
+	header := headerStyle.Render("▸ Raw Exchanges")
+	s.WriteString(header + "\n\n")
+
+	exchanges := m.recorder.Exchanges()
+	if len(exchanges) == 0 {
+		s.WriteString(mutedStyle.Render("  Nothing has been sent on this connection yet") + "\n\n")
+		s.WriteString(renderHint("  b back • q quit"))
+		return s.String()
+	}
+
+	selected := max(0, min(m.inspectorCursor, len(exchanges)-1))
+	first := max(0, min(selected-INSPECTOR_LIST_SIZE/2, len(exchanges)-INSPECTOR_LIST_SIZE))
+	last := min(len(exchanges), first+INSPECTOR_LIST_SIZE)
+	for i := first; i < last; i++ {
+		summary := renderExchangeSummary(exchanges[i])
+		if i == selected {
+			summary = selectedItemStyle.Render(summary)
+		} else {
+			summary = normalItemStyle.Render(summary)
+		}
+		s.WriteString(fmt.Sprintf("  %s %s\n", renderCursor(i == selected), summary))
+	}
+	s.WriteString(mutedStyle.Render(fmt.Sprintf("  %d/%d", selected+1, len(exchanges))) + "\n\n")
+
+	exchange := exchanges[selected]
+
+	s.WriteString(fmt.Sprintf("  %s\n", runningStyle.Render("Sent")))
+	s.WriteString(renderHexDump(exchange.Sent))
+	if len(exchange.Sent) > 0 {
+		if decoded, err := commander.DecodeRequest(exchange.Sent); err != nil {
+			s.WriteString(errorStyle.Render("     "+err.Error()) + "\n")
+		} else if decoded != nil {
+			s.WriteString(renderDecoded(decoded))
+		}
+	}
+	s.WriteString("\n")
+
+	s.WriteString(fmt.Sprintf("  %s\n", runningStyle.Render("Received")))
+	if exchange.Err != nil {
+		s.WriteString(errorStyle.Render("     "+exchange.Err.Error()) + "\n")
+	}
+	s.WriteString(renderHexDump(exchange.Received))
+	if len(exchange.Received) > 0 && len(exchange.Sent) > 0 {
+		if decoded, err := commander.DecodeResponse(exchange.Opcode(), exchange.Received); err != nil {
+			s.WriteString(errorStyle.Render("     "+err.Error()) + "\n")
+		} else {
+			s.WriteString(renderDecoded(decoded))
+		}
+	}
+	s.WriteString("\n")
+
+	s.WriteString(renderHint("  ↑/↓ select exchange • g/G first/last • b back • q quit"))
+
+	return s.String()
+}
@@ -0,0 +1,283 @@
+package terminal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// used until the terminal reports its size
+const DEFAULT_LOG_VIEW_HEIGHT = 20
+
+// lines around the viewport: top margin, summary, search bar and hint
+const LOG_VIEW_RESERVED_LINES = 7
+
+type viewLine struct {
+	Timestamp time.Time
+	Content   string
+	Debug     bool
+}
+
+func (m model) runnerDone() bool {
+	for _, res := range m.results {
+		if res.Status == StatusPending || res.Status == StatusRunning {
+			return false
+		}
+	}
+	return len(m.results) > 0
+}
+
+// reset the viewport for a new run of tests or commands
+func (m model) startLogView() model {
+	m.collapsed = make(map[int]bool)
+	m.search.Reset()
+	m.searching = false
+	m.testOffsets = nil
+	m.logView.SetContent("")
+	m.logView.GotoTop()
+	return m.resizeLogView()
+}
+
+func (m model) resizeLogView() model {
+	if m.width > 0 {
+		m.logView.Width = m.width
+	}
+	if m.height > 0 {
+		reserved := LOG_VIEW_RESERVED_LINES
+		if m.uiState == VIEW_MONITOR {
+			reserved += MONITOR_HEADER_LINES
+		}
+		m.logView.Height = max(m.height-reserved, 3)
+	}
+	return m
+}
+
+// the log lines of one result, with the serial layer's debug lines interleaved when they are shown
+func (m model) resultLines(res TestResult) []viewLine {
+	lines := []viewLine{}
+	for _, log := range res.Logs {
+		lines = append(lines, viewLine{Timestamp: log.Timestamp, Content: strings.TrimSuffix(log.Content, "\n")})
+	}
+
+	if m.showDebug && m.debugLogs != nil && !res.StartTime.IsZero() {
+		for _, entry := range m.debugLogs.Between(res.StartTime, res.EndTime) {
+			lines = append(lines, viewLine{Timestamp: entry.Time, Content: entry.Message, Debug: true})
+		}
+		sort.SliceStable(lines, func(i, j int) bool {
+			return lines[i].Timestamp.Before(lines[j].Timestamp)
+		})
+	}
+
+	query := strings.ToLower(strings.TrimSpace(m.search.Value()))
+	if query == "" {
+		return lines
+	}
+	matching := []viewLine{}
+	for _, line := range lines {
+		if strings.Contains(strings.ToLower(line.Content), query) {
+			matching = append(matching, line)
+		}
+	}
+	return matching
+}
+
+// render every result into the viewport and remember where each header landed
+func (m model) refreshLogView() model {
+	follow := m.logView.AtBottom()
+
+	var s strings.Builder
+	offsets := make([]int, len(m.results))
+	line := 0
+	write := func(text string) {
+		s.WriteString(text + "\n")
+		line++
+	}
+
+	for i, res := range m.results {
+		offsets[i] = line
+
+		icon := renderStatusIcon(res.Status, m.spinner.View())
+		name := renderTestName(res.Name, res.Status)
+		lines := m.resultLines(res)
+
+		details := ""
+		if !res.EndTime.IsZero() {
+			details = mutedStyle.Render(fmt.Sprintf("  %s", res.EndTime.Sub(res.StartTime).Round(time.Millisecond)))
+		}
+		if m.collapsed[i] {
+			details += mutedStyle.Render(fmt.Sprintf("  [%d lines hidden]", len(lines)))
+		}
+		write(fmt.Sprintf("%s %s  %s%s", renderCursor(i == m.cursor), icon, name, details))
+
+		if !m.collapsed[i] {
+			for _, entry := range lines {
+				timestamp := timestampStyle.Render(entry.Timestamp.Format("15:04:05.000"))
+				for j, content := range strings.Split(entry.Content, "\n") {
+					rendered := renderLogLine(content)
+					if entry.Debug {
+						rendered = mutedStyle.Render(content)
+					}
+					if j == 0 {
+						write(fmt.Sprintf("     %s  %s", timestamp, rendered))
+					} else {
+						// Continuation lines without timestamp
+						write(fmt.Sprintf("     %s  %s", strings.Repeat(" ", 12), rendered))
+					}
+				}
+			}
+		}
+		write("")
+	}
+
+	m.logView.SetContent(s.String())
+	m.testOffsets = offsets
+	if follow {
+		m.logView.GotoBottom()
+	}
+	return m
+}
+
+// move the cursor to a result and scroll its header to the top of the viewport
+func (m model) focusResult(index int) model {
+	m.cursor = index
+	m = m.refreshLogView()
+	if index < len(m.testOffsets) {
+		m.logView.SetYOffset(m.testOffsets[index])
+	}
+	return m
+}
+
+// the next failed result after the cursor, wrapping around
+func (m model) nextFailure() (int, bool) {
+	for step := 1; step <= len(m.results); step++ {
+		i := (m.cursor + step) % len(m.results)
+		if m.results[i].Status == StatusFail {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// keys shared by the test and command runners, navigation works while the run is still going
+func (m model) updateLogView(msg tea.Msg) (model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	if m.searching {
+		switch keyMsg.String() {
+		case "enter":
+			m.searching = false
+			m.search.Blur()
+		case "esc":
+			m.searching = false
+			m.search.Reset()
+			m.search.Blur()
+		default:
+			var cmd tea.Cmd
+			m.search, cmd = m.search.Update(msg)
+			return m.refreshLogView(), cmd
+		}
+		return m.refreshLogView(), nil
+	}
+
+	switch keyMsg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			return m.focusResult(m.cursor - 1), nil
+		}
+	case "down", "j":
+		if m.cursor < len(m.results)-1 {
+			return m.focusResult(m.cursor + 1), nil
+		}
+	case "pgup", "ctrl+u":
+		m.logView.HalfPageUp()
+	case "pgdown", "ctrl+d":
+		m.logView.HalfPageDown()
+	case "home", "g":
+		m.logView.GotoTop()
+	case "end", "G":
+		m.logView.GotoBottom()
+	case "enter", " ":
+		m.collapsed[m.cursor] = !m.collapsed[m.cursor]
+		return m.focusResult(m.cursor), nil
+	case "c":
+		for i := range m.results {
+			m.collapsed[i] = true
+		}
+		return m.focusResult(m.cursor), nil
+	case "e":
+		m.collapsed = make(map[int]bool)
+		return m.focusResult(m.cursor), nil
+	case "f":
+		if i, ok := m.nextFailure(); ok {
+			m.collapsed[i] = false
+			return m.focusResult(i), nil
+		}
+	case "/":
+		m.searching = true
+		return m, m.search.Focus()
+	case "d":
+		m.showDebug = !m.showDebug
+		return m.refreshLogView(), nil
+	}
+
+	return m, nil
+}
+
+func (m model) viewRunner(summaryText string, doneHint string) string {
+	var s strings.Builder
+
+	// Just add a small top margin
+	s.WriteString("\n")
+	s.WriteString(m.logView.View() + "\n")
+
+	completed := 0
+	passed := 0
+	for _, res := range m.results {
+		if res.Status == StatusPass || res.Status == StatusFail {
+			completed++
+			if res.Status == StatusPass {
+				passed++
+			}
+		}
+	}
+
+	s.WriteString(mutedStyle.Render("  "+strings.Repeat("─", 40)) + "\n")
+	summaryStyle := runningStyle
+	if m.runnerDone() {
+		summaryStyle = successStyle
+		if passed != completed {
+			summaryStyle = errorStyle
+		}
+	}
+	status := fmt.Sprintf("%d/%d %s", passed, len(m.results), summaryText)
+	if m.showDebug {
+		status += mutedStyle.Render("  • debug logs shown")
+	}
+	s.WriteString(fmt.Sprintf("  %s  %s\n", summaryStyle.Render(status), mutedStyle.Render(fmt.Sprintf("%3.0f%%", m.logView.ScrollPercent()*100))))
+
+	if m.searching || m.search.Value() != "" {
+		s.WriteString("  " + m.search.View() + "\n")
+	} else {
+		s.WriteString("\n")
+	}
+
+	if m.searching {
+		s.WriteString(renderHint("  enter apply • esc clear search"))
+		return s.String()
+	}
+
+	hint := "  ↑/↓ select • pgup/pgdn scroll • enter collapse • c/e collapse/expand all • f next failure • / search • d debug logs • x raw bytes"
+	if m.runnerDone() {
+		s.WriteString(renderHint(hint + " • " + doneHint + " • q quit"))
+	} else {
+		s.WriteString(renderHint(hint + " • q quit"))
+	}
+
+	return s.String()
+}
@@ -0,0 +1,235 @@
+package terminal
+
+import (
+	"UCLA-Rocket-Project/ILAYE/internal/checkout"
+	"UCLA-Rocket-Project/ILAYE/internal/monitor"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/zap"
+)
+
+// lines above the log viewport: title, status, alert banner and the latest alerts
+const MONITOR_HEADER_LINES = 3 + MONITOR_SHOWN_ALERTS
+
+const MONITOR_SHOWN_ALERTS = 3
+
+type monitorAlert struct {
+	Time   time.Time
+	Check  string
+	Reason string
+	Cycle  int
+}
+
+type monitorState struct {
+	monitor *monitor.Monitor
+	cycle   int
+	running bool
+	paused  bool
+	next    time.Time
+
+	// alerts since the operator last acknowledged them, and every alert of the hold
+	unacked int
+	alerts  []monitorAlert
+
+	// ticks of a monitor that was left carry an old generation and are dropped
+	generation int
+}
+
+type monitorTickMsg struct {
+	generation int
+}
+
+type monitorCycleDoneMsg struct{}
+
+func monitorTick(generation int) tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return monitorTickMsg{generation: generation}
+	})
+}
+
+// the monitor of the selected section, loaded and checked against its menus
+func (m model) loadMonitor() (*monitorState, error) {
+	path := m.config.Sections.BodyTube.Monitor
+	if m.selectedSection == checkout.SECTION_NOSE_CONE {
+		path = m.config.Sections.NoseCone.Monitor
+	}
+	mon, err := monitor.Load(path, m.checkout())
+	if err != nil {
+		return nil, err
+	}
+
+	generation := 1
+	if m.monitor != nil {
+		generation = m.monitor.generation + 1
+	}
+	return &monitorState{monitor: mon, next: time.Now(), generation: generation}, nil
+}
+
+func (m model) openMonitor() (model, tea.Cmd) {
+	state, err := m.loadMonitor()
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	m.err = nil
+	m.monitor = state
+	m.uiState = VIEW_MONITOR
+	m = m.startLogView()
+
+	m, cmd := m.startMonitorCycle()
+	return m, tea.Batch(cmd, monitorTick(state.generation))
+}
+
+// run every check of the monitor once, in the background like the test runner
+func (m model) startMonitorCycle() (model, tea.Cmd) {
+	state := m.monitor
+	state.cycle++
+	state.running = true
+	state.next = time.Now().Add(m.config.Monitor.Interval)
+
+	steps := state.monitor.Procedure.Steps
+	m.results = make([]TestResult, len(steps))
+	for i, step := range steps {
+		m.results[i] = TestResult{Name: step.Describe(), Status: StatusPending, Logs: []LogEntry{}}
+	}
+	m.collapsed = make(map[int]bool)
+	m.cursor = 0
+
+	m.logChan = make(chan any)
+	runner := m.checkout()
+	mon := state.monitor
+	go func() {
+		defer close(m.logChan)
+		w := &chanWriter{ch: m.logChan}
+		for i := range steps {
+			w.ch <- TestStartMsg{Index: i}
+			success := mon.RunCheck(runner, w, i)
+			w.ch <- TestResultMsg{Index: i, Success: success}
+		}
+		w.ch <- monitorCycleDoneMsg{}
+	}()
+
+	return m, tea.Batch(waitForLog(m.logChan), m.spinner.Tick)
+}
+
+// raise an alert for a failed check, the reason is the last thing it logged
+func (m model) monitorCheckFinished(msg TestResultMsg) model {
+	if msg.Success || msg.Index < 0 || msg.Index >= len(m.results) {
+		return m
+	}
+
+	res := m.results[msg.Index]
+	reason := "failed"
+	if len(res.Logs) > 0 {
+		reason = strings.TrimSuffix(res.Logs[len(res.Logs)-1].Content, "\n")
+	}
+	alert := monitorAlert{Time: time.Now(), Check: res.Name, Reason: reason, Cycle: m.monitor.cycle}
+	m.monitor.alerts = append(m.monitor.alerts, alert)
+	m.monitor.unacked++
+
+	if m.log != nil {
+		m.log.Warn("Pad hold monitor alert",
+			zap.String("check", alert.Check),
+			zap.String("reason", alert.Reason),
+			zap.Int("cycle", alert.Cycle),
+			zap.String("section", m.selectedSection.String()),
+		)
+	}
+	return m
+}
+
+func (m model) updateMonitorTick(msg monitorTickMsg) (tea.Model, tea.Cmd) {
+	if m.monitor == nil || msg.generation != m.monitor.generation {
+		return m, nil
+	}
+
+	// cycles only start on the monitor screen so they never share the connection with another view
+	state := m.monitor
+	if m.uiState == VIEW_MONITOR && !state.running && !state.paused && !time.Now().Before(state.next) {
+		m, cmd := m.startMonitorCycle()
+		return m, tea.Batch(cmd, monitorTick(msg.generation))
+	}
+	return m, monitorTick(msg.generation)
+}
+
+func (m model) updateMonitor(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok && !m.searching {
+		state := m.monitor
+		switch key.String() {
+		case "x":
+			return m.openInspector(VIEW_MONITOR), nil
+		case "a":
+			state.unacked = 0
+			return m, nil
+		case "p":
+			state.paused = !state.paused
+			return m, nil
+		case "n":
+			if !state.running {
+				m, cmd := m.startMonitorCycle()
+				return m, cmd
+			}
+			return m, nil
+		case "b":
+			if !state.running {
+				// stale ticks see the new generation and stop
+				state.generation++
+				m.uiState = VIEW_SELECT_MODE
+				m.results = nil
+				m.cursor = 0
+			}
+			return m, nil
+		}
+	}
+
+	return m.updateLogView(msg)
+}
+
+func (m model) viewMonitor() string {
+	var s strings.Builder
+	state := m.monitor
+
+	header := headerStyle.Render(fmt.Sprintf("▸ %s (%s)", state.monitor.Procedure.Name, m.selectedSection))
+	s.WriteString(header + "\n")
+
+	status := fmt.Sprintf("cycle %d • every %s • ", state.cycle, m.config.Monitor.Interval)
+	switch {
+	case state.running:
+		status += "checking now"
+	case state.paused:
+		status += "paused"
+	default:
+		status += fmt.Sprintf("next cycle in %s", time.Until(state.next).Round(time.Second))
+	}
+	s.WriteString("  " + mutedStyle.Render(status) + "\n")
+
+	if state.unacked > 0 {
+		latest := state.alerts[len(state.alerts)-1]
+		s.WriteString("  " + errorStyle.Render(fmt.Sprintf("⚠ %d unacknowledged alerts, latest %s %s", state.unacked, latest.Time.Format("15:04:05"), latest.Check)) + "\n")
+	} else {
+		s.WriteString("  " + successStyle.Render(fmt.Sprintf("✓ no new alerts (%d this hold)", len(state.alerts))) + "\n")
+	}
+
+	// the latest alerts, padded so the viewport below keeps its place
+	shown := state.alerts[max(len(state.alerts)-MONITOR_SHOWN_ALERTS, 0):]
+	for i := range MONITOR_SHOWN_ALERTS {
+		if i >= len(shown) {
+			s.WriteString("\n")
+			continue
+		}
+		alert := shown[len(shown)-1-i]
+		line := fmt.Sprintf("    %s  %s: %s", alert.Time.Format("15:04:05"), alert.Check, alert.Reason)
+		if i < state.unacked {
+			s.WriteString(errorStyle.Render(line) + "\n")
+		} else {
+			s.WriteString(mutedStyle.Render(line) + "\n")
+		}
+	}
+
+	summary := fmt.Sprintf("checks passed in cycle %d", state.cycle)
+	s.WriteString(m.viewRunner(summary, "a acknowledge alerts • p pause • n run now • b back"))
+	return s.String()
+}
@@ -1,6 +1,8 @@
 package terminal
 
 import (
+	"strings"
+
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -78,6 +80,15 @@ var (
 	errorStyle = lipgloss.NewStyle().
 			Foreground(colorError).
 			Bold(true)
+
+	warningStyle = lipgloss.NewStyle().
+			Foreground(colorWarning)
+
+	// flashing alarm banner, swapped with errorStyle every tick
+	alarmStyle = lipgloss.NewStyle().
+			Foreground(colorFg).
+			Background(colorError).
+			Bold(true)
 )
 
 // Log styles
@@ -145,6 +156,14 @@ func renderTestName(name string, status TestStatus) string {
 	}
 }
 
+// warnings from the commander are highlighted so they can't be missed
+func renderLogLine(line string) string {
+	if strings.Contains(line, "WARNING") {
+		return errorStyle.Render(line)
+	}
+	return logContentStyle.Render(line)
+}
+
 func renderHint(text string) string {
 	return hintStyle.Render(text)
 }
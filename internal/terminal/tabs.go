@@ -0,0 +1,239 @@
+package terminal
+
+import (
+	"UCLA-Rocket-Project/ILAYE/internal/alarms"
+	"UCLA-Rocket-Project/ILAYE/internal/config"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/zap"
+)
+
+// lines the tab bar and the alarm banner take above every tab
+const TAB_BAR_LINES = 1
+
+// every connection is a model of its own, shown as a tab
+type tab struct {
+	id    int
+	name  string // from the config, the port name is shown otherwise
+	model model
+}
+
+// messages and commands of a tab carry its id so runs on other tabs keep going in the background
+type tabMsg struct {
+	id  int
+	msg tea.Msg
+}
+
+type app struct {
+	tabs   []tab
+	active int
+	nextID int
+
+	config   *config.Config
+	newModel func() model
+
+	// ports open in any tab, one port cannot be shared by two tabs
+	openPorts map[string]bool
+
+	// the alarm engine is shared by every connection, so the banner and the bell live here
+	alarms     *alarms.Engine
+	alarmFlash bool
+	lastBell   time.Time
+
+	width  int
+	height int
+}
+
+func newApp(cfg *config.Config, alarmEngine *alarms.Engine, newModel func() model) *app {
+	return &app{config: cfg, newModel: newModel, openPorts: map[string]bool{}, alarms: alarmEngine}
+}
+
+// wrap the commands of a tab so whatever they return finds its way back to that tab
+func tagCmd(id int, cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		msg := cmd()
+		switch msg := msg.(type) {
+		case nil:
+			return nil
+		case tea.QuitMsg:
+			return msg
+		case tea.BatchMsg:
+			cmds := make([]tea.Cmd, len(msg))
+			for i, cmd := range msg {
+				cmds[i] = tagCmd(id, cmd)
+			}
+			return tea.BatchMsg(cmds)
+		}
+		return tabMsg{id: id, msg: msg}
+	}
+}
+
+// open a tab at the port picker, or connecting straight away when conn is set
+func (a *app) addTab(conn *config.ConnectionConfig) tea.Cmd {
+	m := a.newModel()
+	m.openPorts = a.openPorts
+	a.nextID++
+	t := tab{id: a.nextID}
+
+	cmds := []tea.Cmd{m.Init()}
+	if a.width > 0 {
+		next, _ := m.Update(a.tabSize())
+		m = next.(model)
+	}
+	if conn != nil {
+		t.name = conn.Name
+		next, cmd := m.connect(conn.Port)
+		m = next.(model)
+		cmds = append(cmds, cmd)
+	}
+
+	t.model = m
+	a.tabs = append(a.tabs, t)
+	a.active = len(a.tabs) - 1
+	return tagCmd(t.id, tea.Batch(cmds...))
+}
+
+// close the active tab and release its port, refused while something still uses the connection
+func (a *app) closeTab() {
+	m := a.tabs[a.active].model
+	if len(a.tabs) == 1 || m.busy() {
+		return
+	}
+	if m.serial != nil {
+		if closer, ok := m.serial.(io.Closer); ok {
+			closer.Close()
+		}
+		delete(a.openPorts, m.portName)
+	}
+
+	a.tabs = append(a.tabs[:a.active], a.tabs[a.active+1:]...)
+	a.active = min(a.active, len(a.tabs)-1)
+}
+
+// stop every stream and release every port once the program ends, recordings are only complete once closed
+func (a *app) shutdown() {
+	for _, t := range a.tabs {
+		m := t.model
+		if m.telemetry != nil && m.telemetry.stream != nil {
+			if err := m.telemetry.stream.Stop(); err != nil {
+				m.log.Error("Could not finish the telemetry recording", zap.Error(err))
+			}
+		}
+		if closer, ok := m.serial.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+}
+
+// the window minus the tab bar and the alarm banner
+func (a *app) tabSize() tea.WindowSizeMsg {
+	reserved := TAB_BAR_LINES
+	if a.alarmsArmed() {
+		reserved++
+	}
+	return tea.WindowSizeMsg{Width: a.width, Height: max(a.height-reserved, 0)}
+}
+
+// one tab per configured connection, or a single port picker
+func (a *app) Init() tea.Cmd {
+	cmds := []tea.Cmd{alarmTick()}
+	for _, conn := range a.config.Connections {
+		cmds = append(cmds, a.addTab(&conn))
+	}
+	if len(a.tabs) == 0 {
+		cmds = append(cmds, a.addTab(nil))
+	}
+	a.active = 0
+	return tea.Batch(cmds...)
+}
+
+func (a *app) updateTab(index int, msg tea.Msg) tea.Cmd {
+	next, cmd := a.tabs[index].model.Update(msg)
+	a.tabs[index].model = next.(model)
+	return tagCmd(a.tabs[index].id, cmd)
+}
+
+func (a *app) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tabMsg:
+		for i := range a.tabs {
+			if a.tabs[i].id == msg.id {
+				return a, a.updateTab(i, msg.msg)
+			}
+		}
+		// the tab was closed, whatever it was waiting for is dropped
+		return a, nil
+	case tea.WindowSizeMsg:
+		a.width = msg.Width
+		a.height = msg.Height
+		cmds := []tea.Cmd{}
+		for i := range a.tabs {
+			cmds = append(cmds, a.updateTab(i, a.tabSize()))
+		}
+		return a, tea.Batch(cmds...)
+	case alarmTickMsg:
+		return a, a.updateAlarmTick()
+	case tea.KeyMsg:
+		typing := a.tabs[a.active].model.typing()
+		switch key := msg.String(); {
+		case key == "ctrl+t":
+			return a, a.addTab(nil)
+		case key == "ctrl+w":
+			a.closeTab()
+			return a, nil
+		case key == "tab" && !typing:
+			a.active = (a.active + 1) % len(a.tabs)
+			return a, nil
+		case key == "shift+tab" && !typing:
+			a.active = (a.active + len(a.tabs) - 1) % len(a.tabs)
+			return a, nil
+		case len(key) == 5 && strings.HasPrefix(key, "alt+") && key[4] >= '1' && key[4] <= '9':
+			if index := int(key[4] - '1'); index < len(a.tabs) {
+				a.active = index
+			}
+			return a, nil
+		}
+	}
+
+	return a, a.updateTab(a.active, msg)
+}
+
+func (a *app) View() string {
+	var s strings.Builder
+	s.WriteString(a.viewTabBar())
+	s.WriteString(a.viewAlarmBanner())
+	s.WriteString(a.tabs[a.active].model.View())
+	return s.String()
+}
+
+func (a *app) viewTabBar() string {
+	var s strings.Builder
+	for i, t := range a.tabs {
+		title := t.name
+		if title == "" {
+			title = t.model.portName
+		}
+		if title == "" {
+			title = "new connection"
+		}
+		if t.model.busy() {
+			title += " •"
+		}
+
+		label := fmt.Sprintf(" %d %s ", i+1, title)
+		if i == a.active {
+			s.WriteString(selectedItemStyle.Render(label))
+		} else {
+			s.WriteString(mutedStyle.Render(label))
+		}
+	}
+	s.WriteString(mutedStyle.Render("  ctrl+t new • tab switch • ctrl+w close") + "\n")
+	return s.String()
+}
@@ -0,0 +1,136 @@
+package terminal
+
+import (
+	"UCLA-Rocket-Project/ILAYE/internal/commander"
+	"UCLA-Rocket-Project/ILAYE/internal/telemetry"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// how often the telemetry table is redrawn
+const TELEMETRY_REFRESH_INTERVAL = 250 * time.Millisecond
+
+// packets older than this are shown as stale
+const TELEMETRY_STALE_AFTER = 3 * time.Second
+
+type telemetryState struct {
+	stream    *telemetry.Stream
+	recordDir string
+
+	// ticks of a stream that was stopped carry an old generation and are dropped
+	generation int
+}
+
+type telemetryTickMsg struct {
+	generation int
+}
+
+func telemetryTick(generation int) tea.Cmd {
+	return tea.Tick(TELEMETRY_REFRESH_INTERVAL, func(time.Time) tea.Msg {
+		return telemetryTickMsg{generation: generation}
+	})
+}
+
+// listen to the port and record every packet, nothing is sent to the boards
+func (m model) openTelemetry() (model, tea.Cmd) {
+	frames, err := telemetry.Frames(m.serial)
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+
+	name := telemetry.SessionName("telemetry", m.selectedSection.String(), time.Now())
+	recorder, err := telemetry.NewSessionRecorder(m.config.Telemetry, name, m.log)
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+
+	// the alarm rules keep watching the readings while nothing is polled
+	observers := []commander.TelemetryObserver{}
+	if m.alarms != nil {
+		observers = append(observers, m.alarms.TelemetryLink(m.portName))
+	}
+
+	generation := 1
+	if m.telemetry != nil {
+		generation = m.telemetry.generation + 1
+	}
+	m.err = nil
+	m.telemetry = &telemetryState{
+		stream:     telemetry.Listen(frames, recorder, m.log, observers...),
+		recordDir:  recorder.Dir,
+		generation: generation,
+	}
+	m.uiState = VIEW_TELEMETRY
+	return m, telemetryTick(generation)
+}
+
+func (m model) updateTelemetryTick(msg telemetryTickMsg) (tea.Model, tea.Cmd) {
+	if m.telemetry == nil || m.telemetry.stream == nil || msg.generation != m.telemetry.generation {
+		return m, nil
+	}
+	// nothing to change, the view reads the stream on every redraw
+	return m, telemetryTick(msg.generation)
+}
+
+func (m model) updateTelemetry(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "b", "esc":
+			if err := m.telemetry.stream.Stop(); err != nil {
+				m.err = err
+			}
+			m.telemetry.stream = nil
+			m.uiState = VIEW_SELECT_MODE
+			m.cursor = 0
+		}
+	}
+	return m, nil
+}
+
+func (m model) viewTelemetry() string {
+	var s strings.Builder
+	stream := m.telemetry.stream
+
+	header := headerStyle.Render(fmt.Sprintf("▸ Telemetry (%s)", m.selectedSection))
+	s.WriteString(header + "\n")
+	status := fmt.Sprintf("listening for %s • recording to %s • %d frames not decoded",
+		time.Since(stream.Started()).Round(time.Second), m.telemetry.recordDir, stream.Undecoded())
+	s.WriteString("  " + mutedStyle.Render(status) + "\n\n")
+
+	stats := stream.Stats()
+	if len(stats) == 0 {
+		s.WriteString("  " + mutedStyle.Render("no telemetry yet, this packet format is a proposal and no board firmware streams it yet") + "\n")
+	} else {
+		s.WriteString(mutedStyle.Render(fmt.Sprintf("  %-22s %-8s %8s %8s %6s %7s %14s  %s", "packet", "board", "count", "rate", "gaps", "seq", "board time", "latest")) + "\n")
+	}
+	for _, st := range stats {
+		fields := []string{}
+		for _, field := range st.Last.Decoded.Fields {
+			fields = append(fields, fmt.Sprintf("%s=%s", field.Name, field.Value))
+		}
+
+		line := fmt.Sprintf("  %-22s %-8s %8d %6.1fHz %6d %7d %14d  %s",
+			st.Name, st.Board, st.Count, st.RateHz, st.Gaps, st.Last.Sequence, st.Last.BoardMicros, strings.Join(fields, " "))
+		if m.width > 0 && len(line) > m.width {
+			line = line[:m.width]
+		}
+
+		switch {
+		case time.Since(st.LastAt) > TELEMETRY_STALE_AFTER:
+			s.WriteString(mutedStyle.Render(line) + "\n")
+		case st.Gaps > 0:
+			s.WriteString(warningStyle.Render(line) + "\n")
+		default:
+			s.WriteString(normalItemStyle.Render(line) + "\n")
+		}
+	}
+
+	s.WriteString("\n")
+	s.WriteString(renderHint("  b stop and back • nothing is sent while listening"))
+	return s.String()
+}
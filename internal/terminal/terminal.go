@@ -1,13 +1,19 @@
 package terminal
 
 import (
-	"UCLA-Rocket-Project/ILAYE/internal/globals"
+	"UCLA-Rocket-Project/ILAYE/internal/alarms"
+	"UCLA-Rocket-Project/ILAYE/internal/checkout"
+	"UCLA-Rocket-Project/ILAYE/internal/commander"
+	"UCLA-Rocket-Project/ILAYE/internal/config"
+	"UCLA-Rocket-Project/ILAYE/internal/logger"
 	"fmt"
 	"os"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"go.uber.org/zap"
@@ -24,15 +30,12 @@ const (
 	VIEW_SELECT_COMMANDS
 	VIEW_COMMAND_RUNNER
 	VIEW_LOADING
-)
-
-const FILLER_WHITESPACE = 0xFE
-
-type RocketSection int
-
-const (
-	SECTION_NOSE_CONE RocketSection = iota
-	SECTION_BODY_TUBE
+	VIEW_CHECKLIST
+	VIEW_INSPECTOR
+	VIEW_CONSOLE
+	VIEW_MONITOR
+	VIEW_ALARMS
+	VIEW_TELEMETRY
 )
 
 type SerialReaderWriter interface {
@@ -54,16 +57,28 @@ type model struct {
 	uiState UIState
 	cursor  int
 	err     error
+	config  *config.Config
+	log     *zap.Logger
+
+	// terminal size, used to fit the log viewport
+	width  int
+	height int
 
 	// connect to port internal state
 	potentialPorts []string
 	portName       string
+	addressInput   textinput.Model // bridge address typed in the port picker
+	enteringAddr   bool
 	connector      PortConnector
+	openPorts      map[string]bool // shared by every tab
 	serial         SerialReaderWriter
+	recorder       *commander.Recorder      // wraps serial, keeps every frame for the raw inspector
+	versions       *commander.BoardVersions // nil when the radio did not answer the handshake
+	handshakeLogs  []string
 
 	// rocket section selection
-	selectedSection RocketSection
-	selectedMode    int // 0 = tests, 1 = commands
+	selectedSection checkout.Section
+	selectedMode    int // 0 = tests, 1 = commands, 2 = checklist, 3 = raw inspector, 4 = console, 5 = monitor, 6 = telemetry
 
 	// select tests internal state
 	selectedTests map[int]struct{}
@@ -75,6 +90,39 @@ type model struct {
 	results []TestResult
 	logChan chan any
 
+	// log viewport of the test and command runners
+	logView     viewport.Model
+	testOffsets []int // line of each result header in the viewport
+	collapsed   map[int]bool
+	search      textinput.Model
+	searching   bool
+	showDebug   bool
+	debugLogs   *logger.RingBuffer
+
+	// checklist internal state, shared by pointer so the text input survives model copies
+	checklist *checklistState
+
+	// pad hold monitor internal state, shared by pointer like the checklist
+	monitor *monitorState
+
+	// passive telemetry internal state
+	telemetry *telemetryState
+
+	// alarm engine shared by every tab, the banner and the bell are drawn by the tab container
+	alarms       *alarms.Engine
+	alarmCursor  int
+	alarmsReturn UIState
+
+	// raw inspector internal state
+	inspectorCursor int
+	inspectorReturn UIState
+
+	// raw command console internal state
+	console              textinput.Model
+	consoleEntries       []consoleEntry
+	consoleHistoryCursor int
+	consoleErr           error
+
 	// spinner for loading/running states
 	spinner spinner.Model
 }
@@ -93,6 +141,7 @@ type TestResult struct {
 	Logs      []LogEntry
 	Status    TestStatus
 	StartTime time.Time
+	EndTime   time.Time
 }
 
 // LogEntry represents a single log line with its timestamp
@@ -132,109 +181,55 @@ func waitForLog(sub <-chan any) tea.Cmd {
 	}
 }
 
-type commandAndDesc struct {
-	commandName string
-	opCode      byte
-}
-
 var logPool []string
 
-// Nose Cone tests
-var noseConTests []commandAndDesc = []commandAndDesc{
-	{"Select All", 0xFF},
-	{"--- GENERAL ---", FILLER_WHITESPACE},
-	{"Test uplinker serial connection", globals.CMD_TEST_SERIAL_CONN},
-	{"--- RADIO ---", FILLER_WHITESPACE},
-	{"Get Radio SD Card Update", globals.CMD_GET_RADIO_SD_UPDATE},
-	{"--- DIGITAL V2 ---", FILLER_WHITESPACE},
-	{"Get Digital V2 SD Card Update", globals.CMD_GET_DIGITAL_V2_SD_UPDATE},
-	{"Get Digital V2 Altimeter Reading", globals.CMD_GET_DIGITAL_V2_ALTIMETER_READING},
-	{"Get Digital V2 Shock 1 Reading", globals.CMD_GET_DIGITAL_V2_SHOCK_1_READING},
-	{"Get Digital V2 Shock 2 Reading", globals.CMD_GET_DIGITAL_V2_SHOCK_2_READING},
-	{"Get Digital V2 IMU Reading", globals.CMD_GET_DIGITAL_V2_IMU_READING},
-	{"Get Digital V2 GPS Reading", globals.CMD_GET_DIGITAL_V2_GPS_READING},
+// Helper to bind the connection, config and selected section together
+func (m model) checkout() checkout.Checkout {
+	return checkout.Checkout{Conn: m.serial, Config: m.config, Section: m.selectedSection, Versions: m.versions}
 }
 
-// Body Tube tests
-var bodyTubeTests []commandAndDesc = []commandAndDesc{
-	{"Select All", 0xFF},
-	{"--- GENERAL ---", FILLER_WHITESPACE},
-	{"Test uplinker serial connection", globals.CMD_TEST_SERIAL_CONN},
-	{"--- RADIO ---", FILLER_WHITESPACE},
-	{"Get Radio SD Card Update", globals.CMD_GET_RADIO_SD_UPDATE},
-	{"--- ANALOG V1 ---", FILLER_WHITESPACE},
-	{"Get Analog V1 SD Card Update", globals.CMD_GET_ANALOG_V1_SD_UPDATE},
-	{"Get Analog V1 PT Reading", globals.CMD_GET_ANALOG_V1_PT_READING},
-	{"--- ANALOG V2 ---", FILLER_WHITESPACE},
-	{"Get Analog V2 SD Card Update", globals.CMD_GET_ANALOG_V2_SD_UPDATE},
-	{"Get Analog V2 PT Reading", globals.CMD_GET_ANALOG_V2_PT_READING},
-	{"--- DIGITAL V1 ---", FILLER_WHITESPACE},
-	{"Get Digital V1 SD Card Update", globals.CMD_GET_DIGITAL_V1_SD_UPDATE},
-	{"Get Digital V1 Altimeter Reading", globals.CMD_GET_DIGITAL_V1_ALTIMETER_READING},
-	{"Get Digital V1 Shock 1 Reading", globals.CMD_GET_DIGITAL_V1_SHOCK_1_READING},
-	{"Get Digital V1 IMU Reading", globals.CMD_GET_DIGITAL_V1_IMU_READING},
+// Helper to get the active test list based on selected section
+func (m model) activeTests() []checkout.CommandAndDesc {
+	return m.checkout().Tests()
 }
 
-// Nose Cone commands
-var noseConeCommands []commandAndDesc = []commandAndDesc{
-	{"Select All", 0xFF},
-	{"--- GENERAL ---", FILLER_WHITESPACE},
-	{"Enter Normal Mode", globals.CMD_ENTER_NORMAL},
-	{"Enter Inspect Mode", globals.CMD_ENTER_INSPECT},
-	{"Jump Clock", globals.CMD_JUMP_CLK},
-	{"Prepare for launch (No coming back!)", globals.CMD_ENTER_LAUNCH_MODE},
-	{"--- RADIO ---", FILLER_WHITESPACE},
-	{"Clear Radio SD", globals.CMD_CLEAR_RADIO_SD},
-	{"--- DIGITAL V2 ---", FILLER_WHITESPACE},
-	{"Clear Digital V2 SD", globals.CMD_CLEAR_DIGITAL_V2_SD},
+// Helper to get the active command list based on selected section
+func (m model) activeCommands() []checkout.CommandAndDesc {
+	return m.checkout().Commands()
 }
 
-// Body Tube commands
-var bodyTubeCommands []commandAndDesc = []commandAndDesc{
-	{"Select All", 0xFF},
-	{"--- GENERAL ---", FILLER_WHITESPACE},
-	{"Enter Normal Mode", globals.CMD_ENTER_NORMAL},
-	{"Enter Inspect Mode", globals.CMD_ENTER_INSPECT},
-	{"Jump Clock", globals.CMD_JUMP_CLK},
-	{"Prepare for launch (No coming back!)", globals.CMD_ENTER_LAUNCH_MODE},
-	{"--- RADIO ---", FILLER_WHITESPACE},
-	{"Clear Radio SD", globals.CMD_CLEAR_RADIO_SD},
-	{"--- ANALOG V1 ---", FILLER_WHITESPACE},
-	{"Clear Analog V1 SD", globals.CMD_CLEAR_ANALOG_V1_SD},
-	{"--- ANALOG V2 ---", FILLER_WHITESPACE},
-	{"Clear Analog V2 SD", globals.CMD_CLEAR_ANALOG_V2_SD},
-	{"--- DIGITAL V1 ---", FILLER_WHITESPACE},
-	{"Clear Digital V1 SD", globals.CMD_CLEAR_DIGITAL_V1_SD},
-}
+// last entry of the port picker, opens the bridge address input
+const ENTER_BRIDGE_ADDRESS = "Connect to a serial bridge (host:port)…"
 
-// Helper to get the active test list based on selected section
-func (m model) activeTests() []commandAndDesc {
-	if m.selectedSection == SECTION_NOSE_CONE {
-		return noseConTests
-	}
-	return bodyTubeTests
-}
+var modeOptions = []string{"Run Tests", "Run Commands", "Run Checklist", "Inspect Raw Exchanges", "Raw Command Console", "Monitor Pad Hold"}
 
-// Helper to get the active command list based on selected section
-func (m model) activeCommands() []commandAndDesc {
-	if m.selectedSection == SECTION_NOSE_CONE {
-		return noseConeCommands
+// only listed with telemetry.proposed_stream set, no firmware streams the packet format yet
+const STREAM_TELEMETRY_OPTION = "Stream Telemetry (proposed format, needs firmware support)"
+
+func (m model) modeOptions() []string {
+	if m.config.Telemetry.ProposedStream {
+		return append(append([]string{}, modeOptions...), STREAM_TELEMETRY_OPTION)
 	}
-	return bodyTubeCommands
+	return modeOptions
 }
 
-var modeOptions = []string{"Run Tests", "Run Commands"}
-
-func StartApplication(portLister PortLister, connector PortConnector, logger *zap.Logger) {
-	if _, err := tea.NewProgram(initialModel(portLister, connector)).Run(); err != nil {
-		logger.Fatal("Error starting TUI program", zap.Error(err))
+func StartApplication(portLister PortLister, connector PortConnector, cfg *config.Config, alarmEngine *alarms.Engine, log *zap.Logger, debugLogs *logger.RingBuffer) {
+	newModel := func() model {
+		return initialModel(portLister, connector, cfg, alarmEngine, log, debugLogs)
+	}
+	final, err := tea.NewProgram(newApp(cfg, alarmEngine, newModel)).Run()
+	if a, ok := final.(*app); ok {
+		a.shutdown()
+	}
+	if err != nil {
+		log.Fatal("Error starting TUI program", zap.Error(err))
 		os.Exit(1)
 	}
 }
 
 // TUI tries to use functional programming paradigms, so you return a new model everytime, rather
 // then modify a pointer
-func initialModel(portLister PortLister, connector PortConnector) model {
+func initialModel(portLister PortLister, connector PortConnector, cfg *config.Config, alarmEngine *alarms.Engine, log *zap.Logger, debugLogs *logger.RingBuffer) model {
 	ports, err := portLister()
 
 	if err != nil {
@@ -246,13 +241,34 @@ func initialModel(portLister PortLister, connector PortConnector) model {
 	s.Spinner = spinner.Dot
 	s.Style = runningStyle
 
+	search := textinput.New()
+	search.Prompt = "/"
+	search.Placeholder = "search logs"
+
+	addressInput := textinput.New()
+	addressInput.Prompt = "tcp://"
+	addressInput.Placeholder = "padbox.local:8421"
+
+	// bridges from the config sit after the local ports, typing an address is always last
+	ports = append(ports, cfg.Bridge.Remotes...)
+	ports = append(ports, ENTER_BRIDGE_ADDRESS)
+
 	return model{
 		uiState:          VIEW_LIST_PORTS,
 		potentialPorts:   ports,
 		connector:        connector,
+		config:           cfg,
+		log:              log,
+		alarms:           alarmEngine,
 		selectedTests:    make(map[int]struct{}),
 		selectedCommands: make(map[int]struct{}),
 		spinner:          s,
+		logView:          viewport.New(80, DEFAULT_LOG_VIEW_HEIGHT),
+		collapsed:        make(map[int]bool),
+		search:           search,
+		addressInput:     addressInput,
+		debugLogs:        debugLogs,
+		console:          newConsoleInput(),
 	}
 }
 
@@ -301,6 +317,18 @@ func (m model) View() string {
 		s.WriteString(m.viewSelectCommands())
 	case VIEW_COMMAND_RUNNER:
 		s.WriteString(m.viewCommandRunner())
+	case VIEW_CHECKLIST:
+		s.WriteString(m.viewChecklist())
+	case VIEW_INSPECTOR:
+		s.WriteString(m.viewInspector())
+	case VIEW_CONSOLE:
+		s.WriteString(m.viewConsole())
+	case VIEW_MONITOR:
+		s.WriteString(m.viewMonitor())
+	case VIEW_ALARMS:
+		s.WriteString(m.viewAlarms())
+	case VIEW_TELEMETRY:
+		s.WriteString(m.viewTelemetry())
 	}
 
 	return s.String()
@@ -326,6 +354,11 @@ func (m model) viewPortSelection() string {
 	}
 
 	s.WriteString("\n")
+	if m.enteringAddr {
+		s.WriteString("  " + m.addressInput.View() + "\n\n")
+		s.WriteString(renderHint("  enter connect • esc back"))
+		return s.String()
+	}
 	s.WriteString(renderHint("  ↑/↓ navigate • enter select • q quit"))
 
 	return s.String()
@@ -334,11 +367,16 @@ func (m model) viewPortSelection() string {
 func (m model) viewLoading() string {
 	var s strings.Builder
 
+	message := "Connecting to port"
+	if m.serial != nil {
+		message = "Checking board versions"
+	}
+
 	// Spinner with connecting message
 	spinnerView := m.spinner.View()
 	s.WriteString(fmt.Sprintf("\n  %s  %s\n\n",
 		spinnerView,
-		normalItemStyle.Render("Connecting to port")))
+		normalItemStyle.Render(message)))
 
 	return s.String()
 }
@@ -401,6 +439,8 @@ func (m model) viewSelectSection() string {
 	s.WriteString(rail + bodyColor.Render("  ||   ||") + "\n")
 	s.WriteString(structColor.Render("  ||____|     |______________") + "\n")
 
+	s.WriteString("\n")
+	s.WriteString(m.viewVersions())
 	s.WriteString("\n")
 	s.WriteString(renderHint("  ↑/↓ navigate • enter select • q quit"))
 
@@ -412,7 +452,7 @@ func (m model) viewSelectTests() string {
 
 	// Header with section context
 	sectionName := "Nose Cone"
-	if m.selectedSection == SECTION_BODY_TUBE {
+	if m.selectedSection == checkout.SECTION_BODY_TUBE {
 		sectionName = "Body Tube"
 	}
 	header := headerStyle.Render(fmt.Sprintf("▸ Select Tests to Run (%s)", sectionName))
@@ -422,11 +462,11 @@ func (m model) viewSelectTests() string {
 
 	// Test list with checkboxes
 	for i, test := range tests {
-		if test.opCode == FILLER_WHITESPACE {
-			if test.commandName == "" {
+		if test.OpCode == checkout.FILLER_WHITESPACE {
+			if test.CommandName == "" {
 				s.WriteString("\n")
 			} else {
-				s.WriteString(fmt.Sprintf("\n  %s\n", headerStyle.Bold(true).Render(test.commandName)))
+				s.WriteString(fmt.Sprintf("\n  %s\n", headerStyle.Bold(true).Render(test.CommandName)))
 			}
 			continue
 		}
@@ -435,7 +475,7 @@ func (m model) viewSelectTests() string {
 		_, isSelected := m.selectedTests[i]
 		checkbox := renderCheckbox(isSelected)
 
-		testName := test.commandName
+		testName := test.CommandName
 		if i == m.cursor {
 			testName = selectedItemStyle.Render(testName)
 		} else if isSelected {
@@ -454,70 +494,7 @@ func (m model) viewSelectTests() string {
 }
 
 func (m model) viewTestRunner() string {
-	var s strings.Builder
-
-	// Count completed tests
-	completed := 0
-	passed := 0
-	for _, res := range m.results {
-		if res.Status == StatusPass || res.Status == StatusFail {
-			completed++
-			if res.Status == StatusPass {
-				passed++
-			}
-		}
-	}
-
-	// Just add a small top margin
-	s.WriteString("\n")
-
-	// Test results
-	for _, res := range m.results {
-		// Status icon + test name
-		icon := renderStatusIcon(res.Status, m.spinner.View())
-		name := renderTestName(res.Name, res.Status)
-		s.WriteString(fmt.Sprintf("  %s  %s\n", icon, name))
-
-		// Log entries with timestamps
-		if len(res.Logs) > 0 {
-			for _, log := range res.Logs {
-				timestamp := timestampStyle.Render(log.Timestamp.Format("15:04:05.000"))
-				content := strings.TrimSuffix(log.Content, "\n")
-
-				// Handle multi-line log content
-				lines := strings.Split(content, "\n")
-				for j, line := range lines {
-					if j == 0 {
-						s.WriteString(fmt.Sprintf("     %s  %s\n", timestamp, logContentStyle.Render(line)))
-					} else {
-						// Continuation lines without timestamp
-						s.WriteString(fmt.Sprintf("     %s  %s\n", strings.Repeat(" ", 12), logContentStyle.Render(line)))
-					}
-				}
-			}
-		}
-		s.WriteString("\n")
-	}
-
-	// Test summary
-	if completed == len(m.results) && len(m.results) > 0 {
-		// All tests done - show final summary
-		summaryStyle := successStyle
-		if passed != completed {
-			summaryStyle = errorStyle
-		}
-		s.WriteString(mutedStyle.Render("  "+strings.Repeat("─", 40)) + "\n")
-		s.WriteString(fmt.Sprintf("  %s\n\n", summaryStyle.Render(fmt.Sprintf("%d/%d tests passed", passed, len(m.results)))))
-	}
-
-	// Footer hint - show restart option when tests are done
-	if completed == len(m.results) && len(m.results) > 0 {
-		s.WriteString(renderHint("  r run more tests • q quit"))
-	} else {
-		s.WriteString(renderHint("  q quit"))
-	}
-
-	return s.String()
+	return m.viewRunner("tests passed", "r run more tests")
 }
 
 func (m model) viewSelectMode() string {
@@ -528,7 +505,7 @@ func (m model) viewSelectMode() string {
 	s.WriteString(header + "\n\n")
 
 	// Mode options
-	for i, option := range modeOptions {
+	for i, option := range m.modeOptions() {
 		cursor := renderCursor(i == m.cursor)
 		optionName := option
 		if i == m.cursor {
@@ -550,7 +527,7 @@ func (m model) viewSelectCommands() string {
 
 	// Header with section context
 	sectionName := "Nose Cone"
-	if m.selectedSection == SECTION_BODY_TUBE {
+	if m.selectedSection == checkout.SECTION_BODY_TUBE {
 		sectionName = "Body Tube"
 	}
 	header := headerStyle.Render(fmt.Sprintf("▸ Select Commands to Run (%s)", sectionName))
@@ -560,11 +537,11 @@ func (m model) viewSelectCommands() string {
 
 	// Command list with checkboxes
 	for i, cmd := range commands {
-		if cmd.opCode == FILLER_WHITESPACE {
-			if cmd.commandName == "" {
+		if cmd.OpCode == checkout.FILLER_WHITESPACE {
+			if cmd.CommandName == "" {
 				s.WriteString("\n")
 			} else {
-				s.WriteString(fmt.Sprintf("\n  %s\n", headerStyle.Bold(true).Render(cmd.commandName)))
+				s.WriteString(fmt.Sprintf("\n  %s\n", headerStyle.Bold(true).Render(cmd.CommandName)))
 			}
 			continue
 		}
@@ -573,7 +550,7 @@ func (m model) viewSelectCommands() string {
 		_, isSelected := m.selectedCommands[i]
 		checkbox := renderCheckbox(isSelected)
 
-		cmdName := cmd.commandName
+		cmdName := cmd.CommandName
 		if i == m.cursor {
 			cmdName = selectedItemStyle.Render(cmdName)
 		} else if isSelected {
@@ -592,68 +569,5 @@ func (m model) viewSelectCommands() string {
 }
 
 func (m model) viewCommandRunner() string {
-	var s strings.Builder
-
-	// Count completed commands
-	completed := 0
-	succeeded := 0
-	for _, res := range m.results {
-		if res.Status == StatusPass || res.Status == StatusFail {
-			completed++
-			if res.Status == StatusPass {
-				succeeded++
-			}
-		}
-	}
-
-	// Just add a small top margin
-	s.WriteString("\n")
-
-	// Command results
-	for _, res := range m.results {
-		// Status icon + command name
-		icon := renderStatusIcon(res.Status, m.spinner.View())
-		name := renderTestName(res.Name, res.Status)
-		s.WriteString(fmt.Sprintf("  %s  %s\n", icon, name))
-
-		// Log entries with timestamps
-		if len(res.Logs) > 0 {
-			for _, log := range res.Logs {
-				timestamp := timestampStyle.Render(log.Timestamp.Format("15:04:05.000"))
-				content := strings.TrimSuffix(log.Content, "\n")
-
-				// Handle multi-line log content
-				lines := strings.Split(content, "\n")
-				for j, line := range lines {
-					if j == 0 {
-						s.WriteString(fmt.Sprintf("     %s  %s\n", timestamp, logContentStyle.Render(line)))
-					} else {
-						// Continuation lines without timestamp
-						s.WriteString(fmt.Sprintf("     %s  %s\n", strings.Repeat(" ", 12), logContentStyle.Render(line)))
-					}
-				}
-			}
-		}
-		s.WriteString("\n")
-	}
-
-	// Command summary
-	if completed == len(m.results) && len(m.results) > 0 {
-		// All commands done - show final summary
-		summaryStyle := successStyle
-		if succeeded != completed {
-			summaryStyle = errorStyle
-		}
-		s.WriteString(mutedStyle.Render("  "+strings.Repeat("─", 40)) + "\n")
-		s.WriteString(fmt.Sprintf("  %s\n\n", summaryStyle.Render(fmt.Sprintf("%d/%d commands succeeded", succeeded, len(m.results)))))
-	}
-
-	// Footer hint - show restart option when commands are done
-	if completed == len(m.results) && len(m.results) > 0 {
-		s.WriteString(renderHint("  r run more commands • b back to mode • q quit"))
-	} else {
-		s.WriteString(renderHint("  q quit"))
-	}
-
-	return s.String()
+	return m.viewRunner("commands succeeded", "r run more commands • b back to mode")
 }
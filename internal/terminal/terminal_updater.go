@@ -1,8 +1,11 @@
 package terminal
 
 import (
+	"UCLA-Rocket-Project/ILAYE/internal/checkout"
 	"UCLA-Rocket-Project/ILAYE/internal/commander"
-	"UCLA-Rocket-Project/ILAYE/internal/globals"
+	"UCLA-Rocket-Project/ILAYE/internal/rpSerial"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
@@ -10,11 +13,34 @@ import (
 )
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+
+	// keep the log viewport in step with the results while a run is on screen
+	if next, ok := next.(model); ok && (next.uiState == VIEW_TEST_RUNNER || next.uiState == VIEW_COMMAND_RUNNER || next.uiState == VIEW_MONITOR) {
+		return next.refreshLogView(), cmd
+	}
+	return next, cmd
+}
+
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m.resizeLogView(), nil
 	case tea.KeyMsg:
 		switch msg.String() {
-		case "ctrl+c", "q":
+		case "ctrl+c":
 			return m, tea.Quit
+		case "q":
+			// q is just a letter while the operator is typing initials, notes or a search
+			if !m.typing() {
+				return m, tea.Quit
+			}
+		case "!":
+			if !m.typing() && m.uiState != VIEW_ALARMS && m.alarms != nil {
+				return m.openAlarms(), nil
+			}
 		}
 	case spinner.TickMsg:
 		var cmd tea.Cmd
@@ -36,17 +62,35 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.Index >= 0 && msg.Index < len(m.results) {
 			m.results[msg.Index].Status = StatusRunning
 			m.results[msg.Index].StartTime = time.Now()
+			m.results[msg.Index].EndTime = time.Time{}
 		}
 		return m, waitForLog(m.logChan)
 	case TestResultMsg:
 		if msg.Index >= 0 && msg.Index < len(m.results) {
+			m.results[msg.Index].EndTime = time.Now()
 			if msg.Success {
 				m.results[msg.Index].Status = StatusPass
 			} else {
 				m.results[msg.Index].Status = StatusFail
 			}
 		}
+		if m.uiState == VIEW_CHECKLIST {
+			m = m.checklistStepFinished(msg)
+		}
+		if m.monitor != nil && m.monitor.running {
+			m = m.monitorCheckFinished(msg)
+		}
 		return m, waitForLog(m.logChan)
+	case telemetryTickMsg:
+		return m.updateTelemetryTick(msg)
+	case monitorTickMsg:
+		return m.updateMonitorTick(msg)
+	case monitorCycleDoneMsg:
+		// the cycle can end while the raw inspector is open
+		if m.monitor != nil {
+			m.monitor.running = false
+		}
+		return m, nil
 	}
 
 	switch m.uiState {
@@ -66,12 +110,28 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.updateSelectCommands(msg)
 	case VIEW_COMMAND_RUNNER:
 		return m.updateCommandRunner(msg)
+	case VIEW_CHECKLIST:
+		return m.updateChecklist(msg)
+	case VIEW_INSPECTOR:
+		return m.updateInspector(msg)
+	case VIEW_CONSOLE:
+		return m.updateConsole(msg)
+	case VIEW_MONITOR:
+		return m.updateMonitor(msg)
+	case VIEW_ALARMS:
+		return m.updateAlarms(msg)
+	case VIEW_TELEMETRY:
+		return m.updateTelemetry(msg)
 	}
 
 	return m, nil
 }
 
 func (m model) updatePortSelection(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.enteringAddr {
+		return m.updateAddressInput(msg)
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.String() {
@@ -84,26 +144,66 @@ func (m model) updatePortSelection(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.cursor++
 			}
 		case "enter":
-			m.uiState = VIEW_LOADING
-			return m, tea.Batch(
-				connectToPort(m.connector, m.potentialPorts[m.cursor]),
-				m.spinner.Tick,
-			)
+			if m.potentialPorts[m.cursor] == ENTER_BRIDGE_ADDRESS {
+				m.enteringAddr = true
+				return m, m.addressInput.Focus()
+			}
+			return m.connect(m.potentialPorts[m.cursor])
 		}
 	}
 
 	return m, nil
 }
 
+func (m model) updateAddressInput(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "esc":
+			m.enteringAddr = false
+			m.addressInput.Blur()
+			return m, nil
+		case "enter":
+			addr := strings.TrimSpace(m.addressInput.Value())
+			if !rpSerial.IsNetworkAddress(addr) {
+				m.err = fmt.Errorf("%q is not a host:port bridge address", addr)
+				return m, nil
+			}
+			m.err = nil
+			m.enteringAddr = false
+			m.addressInput.Blur()
+			return m.connect(addr)
+		}
+	}
+
+	var cmd tea.Cmd
+	m.addressInput, cmd = m.addressInput.Update(msg)
+	return m, cmd
+}
+
+// claim the port for this tab and connect to it
+func (m model) connect(port string) (tea.Model, tea.Cmd) {
+	if m.openPorts[port] {
+		m.err = fmt.Errorf("%s is already open in another tab", port)
+		return m, nil
+	}
+	m.openPorts[port] = true
+
+	m.uiState = VIEW_LOADING
+	m.portName = port
+	return m, tea.Batch(connectToPort(m.connector, port), m.spinner.Tick)
+}
+
 func (m model) updateLoading(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case connectionSuccessMsg:
-		m.serial = msg
-		m.cursor = 0
-		m.uiState = VIEW_SELECT_SECTION
-		return m, nil
+		m.recorder = commander.NewRecorder(msg)
+		m.serial = m.recorder
+		return m, runHandshake(m.serial, m.config)
+	case handshakeMsg:
+		return m.updateHandshake(msg)
 	case connectionErrorMsg:
 		m.err = msg
+		delete(m.openPorts, m.portName)
 		m.uiState = VIEW_LIST_PORTS
 		return m, nil
 	}
@@ -119,7 +219,7 @@ func (m model) updateSelectMode(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.cursor--
 			}
 		case "down":
-			if m.cursor < len(modeOptions)-1 {
+			if m.cursor < len(m.modeOptions())-1 {
 				m.cursor++
 			}
 		case "p":
@@ -129,10 +229,36 @@ func (m model) updateSelectMode(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		case "enter":
 			m.selectedMode = m.cursor
-			if m.selectedMode == 0 {
+			switch m.selectedMode {
+			case 0:
 				m.uiState = VIEW_SELECT_TESTS
-			} else {
+			case 1:
 				m.uiState = VIEW_SELECT_COMMANDS
+			case 2:
+				checklist, err := m.loadChecklist()
+				if err != nil {
+					m.err = err
+					return m, nil
+				}
+				m.err = nil
+				m.checklist = checklist
+				m.results = []TestResult{}
+				for _, step := range checklist.procedure.Steps {
+					m.results = append(m.results, TestResult{
+						Name:   step.Describe(),
+						Status: StatusPending,
+						Logs:   []LogEntry{},
+					})
+				}
+				m.uiState = VIEW_CHECKLIST
+			case 3:
+				return m.openInspector(VIEW_SELECT_MODE), nil
+			case 4:
+				return m.openConsole()
+			case 5:
+				return m.openMonitor()
+			case 6:
+				return m.openTelemetry()
 			}
 			m.cursor = 0
 			return m, nil
@@ -155,9 +281,9 @@ func (m model) updateSelectSection(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		case "enter":
 			if m.cursor == 0 {
-				m.selectedSection = SECTION_NOSE_CONE
+				m.selectedSection = checkout.SECTION_NOSE_CONE
 			} else {
-				m.selectedSection = SECTION_BODY_TUBE
+				m.selectedSection = checkout.SECTION_BODY_TUBE
 			}
 			m.cursor = 0
 			m.uiState = VIEW_SELECT_MODE
@@ -175,14 +301,14 @@ func (m model) updateSelectTests(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "up":
 			if m.cursor > 0 {
 				m.cursor--
-				for m.cursor > 0 && tests[m.cursor].opCode == FILLER_WHITESPACE {
+				for m.cursor > 0 && tests[m.cursor].OpCode == checkout.FILLER_WHITESPACE {
 					m.cursor--
 				}
 			}
 		case "down":
 			if m.cursor < len(tests)-1 {
 				m.cursor++
-				for m.cursor < len(tests)-1 && tests[m.cursor].opCode == FILLER_WHITESPACE {
+				for m.cursor < len(tests)-1 && tests[m.cursor].OpCode == checkout.FILLER_WHITESPACE {
 					m.cursor++
 				}
 			}
@@ -199,14 +325,14 @@ func (m model) updateSelectTests(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.selectedTests = make(map[int]struct{})
 			return m, nil
 		case " ":
-			if tests[m.cursor].opCode == FILLER_WHITESPACE {
+			if tests[m.cursor].OpCode == checkout.FILLER_WHITESPACE {
 				return m, nil
 			}
 
 			if m.cursor == 0 {
 				if _, ok := m.selectedTests[m.cursor]; !ok {
 					for i := range len(tests) {
-						if tests[i].opCode != FILLER_WHITESPACE {
+						if tests[i].OpCode != checkout.FILLER_WHITESPACE && !checkout.LongTest(tests[i].OpCode) {
 							m.selectedTests[i] = struct{}{}
 						}
 					}
@@ -232,6 +358,7 @@ func (m model) updateSelectTests(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.uiState = VIEW_TEST_RUNNER
 			m.cursor = 0
 			m.logChan = make(chan any)
+			m = m.startLogView()
 
 			// Initialize results
 			m.results = []TestResult{}
@@ -241,7 +368,7 @@ func (m model) updateSelectTests(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 				if _, ok := m.selectedTests[idx]; ok {
 					m.results = append(m.results, TestResult{
-						Name:   test.commandName,
+						Name:   test.CommandName,
 						Status: StatusPending,
 						Logs:   []LogEntry{},
 					})
@@ -251,6 +378,7 @@ func (m model) updateSelectTests(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Capture the active test list for the goroutine
 			activeTests := tests
 			selectedTests := m.selectedTests
+			runner := m.checkout()
 
 			// Run tests in a separate goroutine
 			go func() {
@@ -267,42 +395,7 @@ func (m model) updateSelectTests(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 					w.ch <- TestStartMsg{Index: resultIdx}
 
-					success := false
-					// Map opCode to commander function
-					switch activeTests[idx].opCode {
-					case globals.CMD_TEST_SERIAL_CONN:
-						success = commander.TestSerialConnection(m.serial, w)
-					case globals.CMD_GET_ANALOG_V1_SD_UPDATE:
-						success = commander.InspectSDCards(m.serial, w, "Analog V1", globals.CMD_GET_ANALOG_V1_SD_UPDATE, false)
-					case globals.CMD_GET_ANALOG_V1_PT_READING:
-						success = commander.CheckAnalogPTCommand(m.serial, w, "Analog V1", globals.CMD_GET_ANALOG_V1_PT_READING)
-					case globals.CMD_GET_ANALOG_V2_SD_UPDATE:
-						success = commander.InspectSDCards(m.serial, w, "Analog V2", globals.CMD_GET_ANALOG_V2_SD_UPDATE, false)
-					case globals.CMD_GET_ANALOG_V2_PT_READING:
-						success = commander.CheckAnalogPTCommand(m.serial, w, "Analog V2", globals.CMD_GET_ANALOG_V2_PT_READING)
-					case globals.CMD_GET_DIGITAL_V1_SD_UPDATE:
-						success = commander.InspectSDCards(m.serial, w, "Digital V1", globals.CMD_GET_DIGITAL_V1_SD_UPDATE, false)
-					case globals.CMD_GET_DIGITAL_V1_SHOCK_1_READING:
-						success = commander.CheckDigitalShockCmd(m.serial, w, "V1", globals.CMD_GET_DIGITAL_V1_SHOCK_1_READING)
-					case globals.CMD_GET_DIGITAL_V2_SHOCK_1_READING:
-						success = commander.CheckDigitalShockCmd(m.serial, w, "V2", globals.CMD_GET_DIGITAL_V2_SHOCK_1_READING)
-					case globals.CMD_GET_DIGITAL_V2_SHOCK_2_READING:
-						success = commander.CheckDigitalShockCmd(m.serial, w, "V2", globals.CMD_GET_DIGITAL_V2_SHOCK_2_READING)
-					case globals.CMD_GET_DIGITAL_V1_IMU_READING:
-						success = commander.CheckDigitalIMUCommand(m.serial, w, "V1", globals.CMD_GET_DIGITAL_V1_IMU_READING)
-					case globals.CMD_GET_DIGITAL_V2_IMU_READING:
-						success = commander.CheckDigitalIMUCommand(m.serial, w, "V2", globals.CMD_GET_DIGITAL_V2_IMU_READING)
-					case globals.CMD_GET_DIGITAL_V1_ALTIMETER_READING:
-						success = commander.CheckDigitalAltimeterCommand(m.serial, w, "V1", globals.CMD_GET_DIGITAL_V1_ALTIMETER_READING)
-					case globals.CMD_GET_DIGITAL_V2_ALTIMETER_READING:
-						success = commander.CheckDigitalAltimeterCommand(m.serial, w, "V2", globals.CMD_GET_DIGITAL_V2_ALTIMETER_READING)
-					case globals.CMD_GET_DIGITAL_V2_GPS_READING:
-						success = commander.CheckDigitalGPSCommand(m.serial, w, "V2", globals.CMD_GET_DIGITAL_V2_GPS_READING)
-					case globals.CMD_GET_DIGITAL_V2_SD_UPDATE:
-						success = commander.InspectSDCards(m.serial, w, "Digital V2", globals.CMD_GET_DIGITAL_V2_SD_UPDATE, false)
-					case globals.CMD_GET_RADIO_SD_UPDATE:
-						success = commander.InspectSDCards(m.serial, w, "Radio", globals.CMD_GET_RADIO_SD_UPDATE, true)
-					}
+					success := runner.RunTest(w, activeTests[idx].OpCode)
 
 					w.ch <- TestResultMsg{Index: resultIdx, Success: success}
 					resultIdx++
@@ -317,17 +410,12 @@ func (m model) updateSelectTests(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m model) updateTestRunner(msg tea.Msg) (tea.Model, tea.Cmd) {
-	// Check if all tests are complete
-	allComplete := true
-	for _, res := range m.results {
-		if res.Status == StatusPending || res.Status == StatusRunning {
-			allComplete = false
-			break
-		}
+	if key, ok := msg.(tea.KeyMsg); ok && !m.searching && key.String() == "x" {
+		return m.openInspector(VIEW_TEST_RUNNER), nil
 	}
 
-	// Auto-return to test selection when all tests are done
-	if allComplete && len(m.results) > 0 {
+	// Results stay on screen until the operator is done reviewing them
+	if key, ok := msg.(tea.KeyMsg); ok && !m.searching && m.runnerDone() && key.String() == "r" {
 		m.uiState = VIEW_SELECT_TESTS
 		m.cursor = 0
 		m.selectedTests = make(map[int]struct{})
@@ -335,7 +423,7 @@ func (m model) updateTestRunner(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
-	return m, nil
+	return m.updateLogView(msg)
 }
 
 func (m model) updateSelectCommands(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -346,14 +434,14 @@ func (m model) updateSelectCommands(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "up":
 			if m.cursor > 0 {
 				m.cursor--
-				for m.cursor > 0 && commands[m.cursor].opCode == FILLER_WHITESPACE {
+				for m.cursor > 0 && commands[m.cursor].OpCode == checkout.FILLER_WHITESPACE {
 					m.cursor--
 				}
 			}
 		case "down":
 			if m.cursor < len(commands)-1 {
 				m.cursor++
-				for m.cursor < len(commands)-1 && commands[m.cursor].opCode == FILLER_WHITESPACE {
+				for m.cursor < len(commands)-1 && commands[m.cursor].OpCode == checkout.FILLER_WHITESPACE {
 					m.cursor++
 				}
 			}
@@ -370,14 +458,14 @@ func (m model) updateSelectCommands(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.selectedCommands = make(map[int]struct{})
 			return m, nil
 		case " ":
-			if commands[m.cursor].opCode == FILLER_WHITESPACE {
+			if commands[m.cursor].OpCode == checkout.FILLER_WHITESPACE {
 				return m, nil
 			}
 
 			if m.cursor == 0 {
 				if _, ok := m.selectedCommands[m.cursor]; !ok {
 					for i := range len(commands) {
-						if commands[i].opCode != FILLER_WHITESPACE {
+						if commands[i].OpCode != checkout.FILLER_WHITESPACE {
 							m.selectedCommands[i] = struct{}{}
 						}
 					}
@@ -400,6 +488,7 @@ func (m model) updateSelectCommands(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.uiState = VIEW_COMMAND_RUNNER
 			m.cursor = 0
 			m.logChan = make(chan any)
+			m = m.startLogView()
 
 			// Initialize results
 			m.results = []TestResult{}
@@ -409,7 +498,7 @@ func (m model) updateSelectCommands(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 				if _, ok := m.selectedCommands[idx]; ok {
 					m.results = append(m.results, TestResult{
-						Name:   cmd.commandName,
+						Name:   cmd.CommandName,
 						Status: StatusPending,
 						Logs:   []LogEntry{},
 					})
@@ -419,6 +508,7 @@ func (m model) updateSelectCommands(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Capture the active command list for the goroutine
 			activeCommands := commands
 			selectedCommands := m.selectedCommands
+			runner := m.checkout()
 
 			// Run commands in a separate goroutine
 			go func() {
@@ -435,27 +525,7 @@ func (m model) updateSelectCommands(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 					w.ch <- TestStartMsg{Index: resultIdx}
 
-					success := false
-					switch activeCommands[idx].opCode {
-					case globals.CMD_ENTER_NORMAL:
-						success = commander.EnterNormalCommand(m.serial, w)
-					case globals.CMD_ENTER_INSPECT:
-						success = commander.EnterInspectCommand(m.serial, w)
-					case globals.CMD_CLEAR_ANALOG_V1_SD:
-						success = commander.ClearSDCard(m.serial, w, "Analog V1", globals.CMD_CLEAR_ANALOG_V1_SD)
-					case globals.CMD_CLEAR_DIGITAL_V1_SD:
-						success = commander.ClearSDCard(m.serial, w, "Digital V1", globals.CMD_CLEAR_DIGITAL_V1_SD)
-					case globals.CMD_CLEAR_ANALOG_V2_SD:
-						success = commander.ClearSDCard(m.serial, w, "Analog V2", globals.CMD_CLEAR_ANALOG_V2_SD)
-					case globals.CMD_CLEAR_DIGITAL_V2_SD:
-						success = commander.ClearSDCard(m.serial, w, "Digital V2", globals.CMD_CLEAR_DIGITAL_V2_SD)
-					case globals.CMD_CLEAR_RADIO_SD:
-						success = commander.ClearSDCard(m.serial, w, "Radio", globals.CMD_CLEAR_RADIO_SD)
-					case globals.CMD_JUMP_CLK:
-						success = commander.JumpClocks(m.serial, w)
-					case globals.CMD_ENTER_LAUNCH_MODE:
-						success = commander.EnterLaunchMode(m.serial, w)
-					}
+					success := runner.RunCommand(w, activeCommands[idx].OpCode)
 
 					w.ch <- TestResultMsg{Index: resultIdx, Success: success}
 					resultIdx++
@@ -470,23 +540,58 @@ func (m model) updateSelectCommands(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m model) updateCommandRunner(msg tea.Msg) (tea.Model, tea.Cmd) {
-	// Check if all commands are complete
-	allComplete := true
-	for _, res := range m.results {
-		if res.Status == StatusPending || res.Status == StatusRunning {
-			allComplete = false
-			break
+	if key, ok := msg.(tea.KeyMsg); ok && !m.searching && key.String() == "x" {
+		return m.openInspector(VIEW_COMMAND_RUNNER), nil
+	}
+
+	// Results stay on screen until the operator is done reviewing them
+	if key, ok := msg.(tea.KeyMsg); ok && !m.searching && m.runnerDone() {
+		switch key.String() {
+		case "r":
+			m.uiState = VIEW_SELECT_COMMANDS
+			m.cursor = 0
+			m.selectedCommands = make(map[int]struct{})
+			m.results = nil
+			return m, nil
+		case "b":
+			m.uiState = VIEW_SELECT_MODE
+			m.cursor = 0
+			m.selectedCommands = make(map[int]struct{})
+			m.results = nil
+			return m, nil
 		}
 	}
 
-	// Auto-return to command selection when all commands are done
-	if allComplete && len(m.results) > 0 {
-		m.uiState = VIEW_SELECT_COMMANDS
-		m.cursor = 0
-		m.selectedCommands = make(map[int]struct{})
-		m.results = nil
-		return m, nil
+	return m.updateLogView(msg)
+}
+
+// whether keys should go to a text input rather than the global bindings
+// a run, handshake or monitor cycle still uses the connection, so the tab cannot be closed
+func (m model) busy() bool {
+	if m.uiState == VIEW_LOADING || (m.monitor != nil && m.monitor.running) || (m.telemetry != nil && m.telemetry.stream != nil) {
+		return true
+	}
+	if len(m.consoleEntries) > 0 && m.consoleEntries[len(m.consoleEntries)-1].Pending {
+		return true
 	}
+	for _, res := range m.results {
+		if res.Status == StatusRunning {
+			return true
+		}
+	}
+	return false
+}
 
-	return m, nil
+func (m model) typing() bool {
+	if m.searching || m.enteringAddr || m.uiState == VIEW_CONSOLE {
+		return true
+	}
+	if m.uiState != VIEW_CHECKLIST || m.checklist == nil {
+		return false
+	}
+	switch m.checklist.stage {
+	case CHECKLIST_OVERRIDE_REASON, CHECKLIST_INITIALS, CHECKLIST_NOTES:
+		return true
+	}
+	return false
 }
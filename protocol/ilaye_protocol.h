@@ -0,0 +1,184 @@
+/* Code generated by cmd/protogen from protocol/protocol.yaml. DO NOT EDIT. */
+
+#ifndef ILAYE_PROTOCOL_H
+#define ILAYE_PROTOCOL_H
+
+#include <stdint.h>
+
+/* every command frame ends with this terminator */
+#define ILAYE_FRAME_TERMINATOR "+++"
+
+/* reported in the version reply, ILAYE refuses a radio on any other version */
+#define ILAYE_PROTOCOL_VERSION 1
+
+/* all possible command sequences */
+#define CMD_ENTER_NORMAL 0x00
+#define CMD_ENTER_INSPECT 0x01
+#define CMD_JUMP_CLK 0x0B
+#define CMD_TEST_SERIAL_CONN 0x0C
+#define CMD_ENTER_LAUNCH_MODE 0x04
+#define CMD_GET_VERSION 0x0D
+
+/* radio */
+#define CMD_GET_RADIO_SD_UPDATE 0x20
+#define CMD_CLEAR_RADIO_SD 0x2E
+
+/* analog v1 */
+#define CMD_GET_ANALOG_V1_SD_UPDATE 0xA0
+#define CMD_GET_ANALOG_V1_PT_READING 0xA2
+#define CMD_CLEAR_ANALOG_V1_SD 0xAE
+
+/* digital v1 */
+#define CMD_GET_DIGITAL_V1_SD_UPDATE 0xB0
+#define CMD_GET_DIGITAL_V1_ALTIMETER_READING 0xB1
+#define CMD_GET_DIGITAL_V1_SHOCK_1_READING 0xB3
+#define CMD_GET_DIGITAL_V1_IMU_READING 0xB5
+#define CMD_CLEAR_DIGITAL_V1_SD 0xBE
+
+/* analog v2 */
+#define CMD_GET_ANALOG_V2_SD_UPDATE 0xC0
+#define CMD_GET_ANALOG_V2_PT_READING 0xC2
+#define CMD_CLEAR_ANALOG_V2_SD 0xCE
+
+/* digital v2 */
+#define CMD_GET_DIGITAL_V2_SD_UPDATE 0xD0
+#define CMD_GET_DIGITAL_V2_ALTIMETER_READING 0xD1
+#define CMD_GET_DIGITAL_V2_GPS_READING 0xD2
+#define CMD_GET_DIGITAL_V2_SHOCK_1_READING 0xD3
+#define CMD_GET_DIGITAL_V2_SHOCK_2_READING 0xD4
+#define CMD_GET_DIGITAL_V2_IMU_READING 0xD5
+#define CMD_CLEAR_DIGITAL_V2_SD 0xDE
+
+/* replies sent in place of the expected struct */
+#define CMD_TIMEOUT 0xFF
+#define RADIO_COMMAND_NOT_RECOGNIZED 0xFE
+#define RADIO_COMMAND_INCORRECT 0xFD
+#define RADIO_START_OR_END_WRONG 0xFC
+#define CAN_RESPONSE_WRONG 0xFB
+#define RADIO_COMMAND_ERROR 0xFA
+
+/* telemetry packets streamed in normal mode, a telemetry_header_t then the struct */
+#define TLM_ANALOG_PT 0x70 /* pt_update_t */
+#define TLM_DIGITAL_ALTIMETER 0x71 /* altimeter_data_t */
+#define TLM_DIGITAL_IMU 0x72 /* imu_data_t */
+#define TLM_DIGITAL_GPS 0x73 /* gps_data_t */
+#define TLM_DIGITAL_SHOCK_1 0x74 /* shock_data_t */
+#define TLM_DIGITAL_SHOCK_2 0x75 /* shock_data_t */
+
+/*
+ * the opcode echoed back once a command is done
+ */
+typedef struct __attribute__((packed)) {
+    uint8_t code;
+} ack_reply_t;
+_Static_assert(sizeof(ack_reply_t) == 1, "ack_reply_t must match ILAYE");
+
+/*
+ * board time after a jump or clock read
+ */
+typedef struct __attribute__((packed)) {
+    int64_t board_micros;
+} board_clock_reply_t;
+_Static_assert(sizeof(board_clock_reply_t) == 8, "board_clock_reply_t must match ILAYE");
+
+/*
+ * protocol version and firmware of every board, firmware is major << 8 | minor
+ * a revision of 0 means that board did not answer
+ */
+typedef struct __attribute__((packed)) {
+    uint16_t protocol_version;
+    uint16_t radio_firmware;
+    uint8_t analog_revision;
+    uint16_t analog_firmware;
+    uint8_t digital_revision;
+    uint16_t digital_firmware;
+} version_reply_t;
+_Static_assert(sizeof(version_reply_t) == 10, "version_reply_t must match ILAYE");
+
+/*
+ * payload of the jump clock command, followed by +++
+ */
+typedef struct __attribute__((packed)) {
+    uint8_t command_code;
+    int64_t current_time_stamp_micros;
+} jump_clock_uplink_paylod_t;
+_Static_assert(sizeof(jump_clock_uplink_paylod_t) == 9, "jump_clock_uplink_paylod_t must match ILAYE");
+
+/*
+ * to verify that the SD card is working
+ * 1. Check the current file size and the timestamp
+ * 2. Return the system back to normal mode and wait for 10s
+ * 3. Send the system back into inspect mode
+ * 4. Check the new file size and timestamp, it should be bigger than the previous one
+ */
+typedef struct __attribute__((packed)) {
+    int64_t last_timestamp;
+    uint32_t file_size;
+} sd_update_t;
+_Static_assert(sizeof(sd_update_t) == 12, "sd_update_t must match ILAYE");
+
+/*
+ * free space on the SD card after a clear, in MB
+ */
+typedef struct __attribute__((packed)) {
+    uint32_t free_mb;
+} sd_free_space_reply_t;
+_Static_assert(sizeof(sd_free_space_reply_t) == 4, "sd_free_space_reply_t must match ILAYE");
+
+typedef struct __attribute__((packed)) {
+    uint8_t code;
+    uint8_t a1_state;
+    uint8_t a2_state;
+    uint8_t d1_state;
+    uint8_t d2_state;
+} mode_transition_error_response_t;
+_Static_assert(sizeof(mode_transition_error_response_t) == 5, "mode_transition_error_response_t must match ILAYE");
+
+/*
+ * in front of every telemetry packet, the sequence number counts packets of
+ * each type and wraps around, board time is in microseconds
+ */
+typedef struct __attribute__((packed)) {
+    uint8_t type;
+    uint16_t sequence;
+    int64_t board_micros;
+} telemetry_header_t;
+_Static_assert(sizeof(telemetry_header_t) == 11, "telemetry_header_t must match ILAYE");
+
+typedef struct __attribute__((packed)) {
+    float ch[3];
+} pt_update_t;
+_Static_assert(sizeof(pt_update_t) == 12, "pt_update_t must match ILAYE");
+
+typedef struct __attribute__((packed)) {
+    float acc_x;
+    float acc_y;
+    float acc_z;
+} shock_data_t;
+_Static_assert(sizeof(shock_data_t) == 12, "shock_data_t must match ILAYE");
+
+typedef struct __attribute__((packed)) {
+    float acc_x;
+    float acc_y;
+    float acc_z;
+    float gyr_x;
+    float gyr_y;
+    float gyr_z;
+    uint32_t timestamp;
+} imu_data_t;
+_Static_assert(sizeof(imu_data_t) == 28, "imu_data_t must match ILAYE");
+
+typedef struct __attribute__((packed)) {
+    int32_t temp;
+    int32_t pressure;
+    uint32_t timestamp;
+} altimeter_data_t;
+_Static_assert(sizeof(altimeter_data_t) == 12, "altimeter_data_t must match ILAYE");
+
+typedef struct __attribute__((packed)) {
+    int32_t lat;
+    int32_t lon;
+} gps_data_t;
+_Static_assert(sizeof(gps_data_t) == 8, "gps_data_t must match ILAYE");
+
+#endif /* ILAYE_PROTOCOL_H */